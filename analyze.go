@@ -0,0 +1,158 @@
+package block_stm
+
+import (
+	"bytes"
+	"container/heap"
+	"fmt"
+	"sort"
+)
+
+// TraceAnalysis is the conflict analysis of a recorded block, assuming every transaction has the same cost.
+type TraceAnalysis struct {
+	Txs int
+	// CriticalPath is the length of the longest chain of read-after-write dependencies.
+	CriticalPath int
+	// ReExecutions is the number of extra incarnations in the recorded execution.
+	ReExecutions int
+	// HotKeys are the keys causing the most dependencies, store name -> sorted by conflicts in descending order.
+	HotKeys map[string][]KeyConflicts
+
+	// txn -> the txns it depends on
+	deps [][]TxnIndex
+}
+
+type KeyConflicts struct {
+	Key Key
+	// Conflicts is the number of transactions reading the key written by another transaction in the block.
+	Conflicts int
+}
+
+// AnalyzeTrace computes the dependencies between the transactions from the final read sets, keeps the `topK` hottest
+// keys for each store, returns an error if `topK` is negative, the trace refers to an unknown store, or a transaction
+// depends on itself or a later transaction.
+func AnalyzeTrace(trace *BlockTrace, topK int) (*TraceAnalysis, error) {
+	if topK < 0 {
+		return nil, fmt.Errorf("invalid number of hot keys: %d", topK)
+	}
+
+	a := &TraceAnalysis{
+		Txs:     len(trace.Txs),
+		HotKeys: make(map[string][]KeyConflicts),
+		deps:    make([][]TxnIndex, len(trace.Txs)),
+	}
+
+	// store index -> key -> conflicts
+	conflicts := make(map[int]map[string]int)
+	depth := make([]int, len(trace.Txs))
+	for txn, tx := range trace.Txs {
+		a.ReExecutions += tx.ReExecutions()
+
+		deps := make(map[TxnIndex]struct{})
+		for store, rs := range tx.ReadSet {
+			if store < 0 || store >= len(trace.Stores) {
+				return nil, fmt.Errorf("txn %d reads unknown store %d", txn, store)
+			}
+			// a key read multiple times by the txn is counted once
+			seen := make(map[string]struct{})
+			observe := func(reads []ReadDescriptor) {
+				for _, desc := range reads {
+					if !desc.Version.Valid() {
+						continue
+					}
+					deps[desc.Version.Index] = struct{}{}
+					if _, ok := seen[string(desc.Key)]; ok {
+						continue
+					}
+					seen[string(desc.Key)] = struct{}{}
+					if conflicts[store] == nil {
+						conflicts[store] = make(map[string]int)
+					}
+					conflicts[store][string(desc.Key)]++
+				}
+			}
+			observe(rs.Reads)
+			for _, desc := range rs.Iterators {
+				observe(desc.Reads)
+			}
+		}
+
+		for dep := range deps {
+			if dep >= TxnIndex(txn) {
+				return nil, fmt.Errorf("txn %d depends on txn %d", txn, dep)
+			}
+			a.deps[txn] = append(a.deps[txn], dep)
+			depth[txn] = max(depth[txn], depth[dep])
+		}
+		sort.Slice(a.deps[txn], func(i, j int) bool { return a.deps[txn][i] < a.deps[txn][j] })
+		depth[txn]++
+		a.CriticalPath = max(a.CriticalPath, depth[txn])
+	}
+
+	for store, keys := range conflicts {
+		hot := make([]KeyConflicts, 0, len(keys))
+		for key, n := range keys {
+			hot = append(hot, KeyConflicts{Key(key), n})
+		}
+		sort.Slice(hot, func(i, j int) bool {
+			if hot[i].Conflicts != hot[j].Conflicts {
+				return hot[i].Conflicts > hot[j].Conflicts
+			}
+			return bytes.Compare(hot[i].Key, hot[j].Key) < 0
+		})
+		if len(hot) > topK {
+			hot = hot[:topK]
+		}
+		a.HotKeys[trace.Stores[store].Name] = hot
+	}
+
+	return a, nil
+}
+
+// Parallelism returns the theoretical parallelism with unlimited executors.
+func (a *TraceAnalysis) Parallelism() float64 {
+	if a.CriticalPath == 0 {
+		return 1
+	}
+	return float64(a.Txs) / float64(a.CriticalPath)
+}
+
+// Speedup estimates the speedup over sequential execution with the number of executors, by simulating a list
+// scheduling in transaction order, each transaction starts after its dependencies finish.
+func (a *TraceAnalysis) Speedup(executors int) float64 {
+	if a.Txs == 0 || executors <= 0 {
+		return 1
+	}
+
+	finish := make([]int, a.Txs)
+	free := make(intHeap, executors)
+	var makespan int
+	for txn := range finish {
+		start := heap.Pop(&free).(int)
+		for _, dep := range a.deps[txn] {
+			start = max(start, finish[dep])
+		}
+		finish[txn] = start + 1
+		heap.Push(&free, finish[txn])
+		makespan = max(makespan, finish[txn])
+	}
+	return float64(a.Txs) / float64(makespan)
+}
+
+// intHeap is a min-heap of the time the executors become free.
+type intHeap []int
+
+func (h intHeap) Len() int           { return len(h) }
+func (h intHeap) Less(i, j int) bool { return h[i] < h[j] }
+func (h intHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *intHeap) Push(x any) {
+	*h = append(*h, x.(int))
+}
+
+func (h *intHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
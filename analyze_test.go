@@ -0,0 +1,101 @@
+package block_stm
+
+import (
+	"context"
+	"testing"
+
+	storetypes "cosmossdk.io/store/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestAnalyzeTrace(t *testing.T) {
+	read := func(key string, txn TxnIndex) ReadDescriptor {
+		return ReadDescriptor{Key(key), TxnVersion{Index: txn}}
+	}
+	// 0 <- 1 <- 3, 2 is independent, 4 reads from 0 and 2
+	trace := &BlockTrace{
+		Stores: []StoreTrace{{Name: "acc"}, {Name: "bank"}},
+		Txs: []TxnTrace{
+			{ReadSet: MultiReadSet{0: {Reads: []ReadDescriptor{{Key("a"), InvalidTxnVersion}}}}},
+			{Incarnations: []IncarnationTrace{{Conflict: &ReadConflict{Reason: AbortReadChanged, Key: Key("a")}}, {}}, ReadSet: MultiReadSet{0: {Reads: []ReadDescriptor{read("a", 0), read("a", 0)}}}},
+			{},
+			{ReadSet: MultiReadSet{1: {Iterators: []IteratorDescriptor{{Reads: []ReadDescriptor{read("b", 1)}}}}}},
+			{ReadSet: MultiReadSet{0: {Reads: []ReadDescriptor{read("a", 0), read("c", 2)}}}},
+		},
+	}
+
+	a, err := AnalyzeTrace(trace, 1)
+	require.NoError(t, err)
+	require.Equal(t, 5, a.Txs)
+	require.Equal(t, 3, a.CriticalPath)
+	require.Equal(t, 1, a.ReExecutions)
+	require.Equal(t, map[string][]KeyConflicts{
+		"acc":  {{Key("a"), 2}},
+		"bank": {{Key("b"), 1}},
+	}, a.HotKeys)
+	require.Equal(t, 5.0/3.0, a.Parallelism())
+
+	require.Equal(t, 1.0, a.Speedup(1))
+	// [0, 2], [1, 4], [3]
+	require.Equal(t, 5.0/3.0, a.Speedup(2))
+	require.Equal(t, 5.0/3.0, a.Speedup(10))
+}
+
+func TestAnalyzeInvalidTrace(t *testing.T) {
+	stores := []StoreTrace{{Name: "acc"}}
+	for _, tc := range []struct {
+		name string
+		txs  []TxnTrace
+		err  string
+	}{
+		{
+			"unknown store",
+			[]TxnTrace{{ReadSet: MultiReadSet{1: {}}}},
+			"txn 0 reads unknown store 1",
+		},
+		{
+			"self dependency",
+			[]TxnTrace{{ReadSet: MultiReadSet{0: {Reads: []ReadDescriptor{{Key("a"), TxnVersion{Index: 0}}}}}}},
+			"txn 0 depends on txn 0",
+		},
+		{
+			"forward dependency",
+			[]TxnTrace{
+				{ReadSet: MultiReadSet{0: {Iterators: []IteratorDescriptor{
+					{Reads: []ReadDescriptor{{Key("a"), TxnVersion{Index: 5}}}},
+				}}}},
+			},
+			"txn 0 depends on txn 5",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := AnalyzeTrace(&BlockTrace{Stores: stores, Txs: tc.txs}, 1)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+
+	_, err := AnalyzeTrace(&BlockTrace{Stores: stores}, -1)
+	require.EqualError(t, err, "invalid number of hot keys: -1")
+}
+
+func TestAnalyzeRecordedTrace(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	for _, tc := range []struct {
+		blk          *MockBlock
+		criticalPath int
+	}{
+		{noConflictBlock(100), 1},
+		{worstCaseBlock(100), 100},
+	} {
+		var trace *BlockTrace
+		require.NoError(t, ExecuteBlock(
+			context.Background(), tc.blk.Size(), stores, NewMultiMemDB(stores), 10, tc.blk.ExecuteTx,
+			WithTrace(func(tr *BlockTrace) {
+				trace = tr
+			}),
+		))
+		a, err := AnalyzeTrace(trace, 10)
+		require.NoError(t, err)
+		require.Equal(t, tc.criticalPath, a.CriticalPath)
+	}
+}
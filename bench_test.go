@@ -15,17 +15,22 @@ func BenchmarkBlockSTM(b *testing.B) {
 		key := storetypes.NewKVStoreKey(strconv.FormatInt(int64(i), 10))
 		stores[key] = i + 2
 	}
-	storage := NewMultiMemDB(stores)
-	testCases := []struct {
-		name  string
-		block *MockBlock
-	}{
-		{"random-10000/100", testBlock(10000, 100)},
-		{"no-conflict-10000", noConflictBlock(10000)},
-		{"worst-case-10000", worstCaseBlock(10000)},
-		{"iterate-10000/100", iterateBlock(10000, 100)},
+	type testCase struct {
+		name   string
+		block  *MockBlock
+		stores map[storetypes.StoreKey]int
+	}
+	testCases := []testCase{
+		{"random-10000/100", testBlock(10000, 100), stores},
+		{"no-conflict-10000", noConflictBlock(10000), stores},
+		{"worst-case-10000", worstCaseBlock(10000), stores},
+		{"iterate-10000/100", iterateBlock(10000, 100), stores},
+	}
+	for _, w := range benchWorkloads() {
+		testCases = append(testCases, testCase{w.name, w.Block, w.Stores})
 	}
 	for _, tc := range testCases {
+		storage := NewMultiMemDB(tc.stores)
 		b.Run(tc.name+"-sequential", func(b *testing.B) {
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
@@ -38,7 +43,7 @@ func BenchmarkBlockSTM(b *testing.B) {
 				for i := 0; i < b.N; i++ {
 					require.NoError(
 						b,
-						ExecuteBlock(context.Background(), tc.block.Size(), stores, storage, worker, tc.block.ExecuteTx),
+						ExecuteBlock(context.Background(), tc.block.Size(), tc.stores, storage, worker, tc.block.ExecuteTx),
 					)
 				}
 			})
@@ -46,6 +51,42 @@ func BenchmarkBlockSTM(b *testing.B) {
 	}
 }
 
+type namedWorkload struct {
+	name string
+	*Workload
+}
+
+func benchWorkloads() []namedWorkload {
+	zipf := DefaultWorkloadSpec()
+	zipf.Txs = 10000
+	zipf.Distribution = DistributionZipfian
+
+	hotspot := DefaultWorkloadSpec()
+	hotspot.Txs = 10000
+	hotspot.Distribution = DistributionHotspot
+
+	mixed := DefaultWorkloadSpec()
+	mixed.Txs = 10000
+	mixed.Stores = 8
+	mixed.ObjectStores = 2
+	mixed.ObjectRatio = 0.2
+	mixed.IteratorRatio = 0.1
+
+	return []namedWorkload{
+		{"workload-zipf-10000", mustNewWorkload(zipf)},
+		{"workload-hotspot-10000", mustNewWorkload(hotspot)},
+		{"workload-mixed-10000", mustNewWorkload(mixed)},
+	}
+}
+
+func mustNewWorkload(spec WorkloadSpec) *Workload {
+	w, err := NewWorkload(spec)
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
 func runSequential(storage MultiStore, block *MockBlock) {
 	for i, tx := range block.Txs {
 		block.Results[i] = tx(storage)
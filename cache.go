@@ -0,0 +1,87 @@
+package block_stm
+
+import (
+	"bytes"
+	"sync"
+)
+
+// ExecutionCache retains the optimistic executions of blocks, e.g. executed in `ProcessProposal`, so they can be
+// committed in `FinalizeBlock` without re-execution. R is the type of the per-block results collected by the caller.
+// It's thread-safe.
+type ExecutionCache[R any] struct {
+	mtx     sync.Mutex
+	entries map[string]*cachedExecution[R]
+}
+
+type cachedExecution[R any] struct {
+	// identifies the parent state the block is executed on
+	base    []byte
+	pending *PendingBlock
+	result  R
+}
+
+func NewExecutionCache[R any]() *ExecutionCache[R] {
+	return &ExecutionCache[R]{
+		entries: make(map[string]*cachedExecution[R]),
+	}
+}
+
+// Put caches a dry-run execution of the block, `base` identifies the parent state it's executed on, e.g. the app hash
+// of the last committed block, it replaces the previous execution of the same block.
+func (c *ExecutionCache[R]) Put(blockID, base []byte, pending *PendingBlock, result R) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if prev, ok := c.entries[string(blockID)]; ok {
+		prev.pending.Discard()
+	}
+	c.entries[string(blockID)] = &cachedExecution[R]{
+		base:    base,
+		pending: pending,
+		result:  result,
+	}
+}
+
+// Take removes the cached execution of the block and returns it if the parent state matches,
+// all the other cached executions are discarded, because they are obsolete once a block is decided.
+func (c *ExecutionCache[R]) Take(blockID, base []byte) (*PendingBlock, R, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	var (
+		pending *PendingBlock
+		result  R
+		found   bool
+	)
+	for id, entry := range c.entries {
+		if id == string(blockID) && bytes.Equal(entry.base, base) {
+			pending, result, found = entry.pending, entry.result, true
+			continue
+		}
+		entry.pending.Discard()
+	}
+	clear(c.entries)
+	return pending, result, found
+}
+
+// Len returns the number of cached executions.
+func (c *ExecutionCache[R]) Len() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return len(c.entries)
+}
+
+// Finalize commits the cached execution of the block into storage if found and the parent state matches, otherwise
+// falls back to `execute`, which should execute the block normally. Returns if the cached execution is reused.
+func (c *ExecutionCache[R]) Finalize(
+	blockID, base []byte, storage MultiStore,
+	execute func() (R, error),
+) (R, bool, error) {
+	if pending, result, ok := c.Take(blockID, base); ok {
+		pending.Commit(storage)
+		return result, true, nil
+	}
+
+	result, err := execute()
+	return result, false, err
+}
@@ -0,0 +1,64 @@
+package block_stm
+
+import (
+	"context"
+	"testing"
+
+	storetypes "cosmossdk.io/store/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestExecutionCache(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	blk := testBlock(100, 10)
+	storage := NewMultiMemDB(stores)
+	cache := NewExecutionCache[[]error]()
+
+	optimistic := func(blockID, base []byte) {
+		require.NoError(t, ExecuteBlock(
+			context.Background(), blk.Size(), stores, storage, 10, blk.ExecuteTx,
+			WithDryRun(func(p *PendingBlock) {
+				cache.Put(blockID, base, p, append([]error(nil), blk.Results...))
+			}),
+		))
+	}
+	optimistic([]byte("block1"), []byte("base"))
+	optimistic([]byte("block2"), []byte("base"))
+	require.Equal(t, 2, cache.Len())
+
+	var executed bool
+	execute := func() ([]error, error) {
+		executed = true
+		err := ExecuteBlock(context.Background(), blk.Size(), stores, storage, 10, blk.ExecuteTx)
+		return blk.Results, err
+	}
+
+	// base mismatch, fallback to execution
+	optimistic([]byte("block1"), []byte("base"))
+	result, reused, err := cache.Finalize([]byte("block1"), []byte("other"), NewMultiMemDB(stores), execute)
+	require.NoError(t, err)
+	require.False(t, reused)
+	require.True(t, executed)
+	require.Len(t, result, blk.Size())
+	require.Equal(t, 0, cache.Len())
+
+	// the fallback execution above committed into the storage, start over with a fresh one
+	storage = NewMultiMemDB(stores)
+	optimistic([]byte("block1"), []byte("base"))
+	for store := range stores {
+		require.True(t, StoreEqual(NewMemDB(), storage.GetKVStore(store)))
+	}
+
+	executed = false
+	result, reused, err = cache.Finalize([]byte("block1"), []byte("base"), storage, execute)
+	require.NoError(t, err)
+	require.True(t, reused)
+	require.False(t, executed)
+	require.Len(t, result, blk.Size())
+
+	crossCheck := NewMultiMemDB(stores)
+	runSequential(crossCheck, blk)
+	for store := range stores {
+		require.True(t, StoreEqual(crossCheck.GetKVStore(store), storage.GetKVStore(store)))
+	}
+}
@@ -0,0 +1,47 @@
+package block_stm
+
+import storetypes "cosmossdk.io/store/types"
+
+// ChangeSet is the final writes of a transaction, grouped by store and sorted by store name,
+// the stores not written by the transaction are omitted.
+type ChangeSet struct {
+	Stores []StoreChangeSet
+}
+
+// StoreChangeSet is the writes to a single store, sorted by key, `nil` value means deleted.
+// The values are `[]byte` for kv stores, and the original objects for object stores.
+type StoreChangeSet struct {
+	Store storetypes.StoreKey
+	Pairs []GKVPair[any]
+}
+
+// ChangeSets returns the change sets of the final incarnations of all the transactions in order,
+// should only be called after the block is executed.
+func (mv *MVMemory) ChangeSets() []ChangeSet {
+	stores := mv.sortedStores()
+	changeSets := make([]ChangeSet, len(mv.lastWrittenLocations))
+	for txn := range mv.lastWrittenLocations {
+		changeSets[txn] = mv.txnChangeSet(TxnIndex(txn), stores)
+	}
+	return changeSets
+}
+
+// txnChangeSet returns the change set of the final incarnation of a transaction,
+// stores are the store keys sorted by name.
+func (mv *MVMemory) txnChangeSet(txn TxnIndex, stores []storetypes.StoreKey) ChangeSet {
+	var cs ChangeSet
+	locations := mv.readLastWrittenLocations(txn)
+	for _, name := range stores {
+		i := mv.stores[name]
+		if len(locations[i]) == 0 {
+			continue
+		}
+
+		pairs := mv.data[i].TxnWrites(txn, locations[i])
+		if len(pairs) == 0 {
+			continue
+		}
+		cs.Stores = append(cs.Stores, StoreChangeSet{Store: name, Pairs: pairs})
+	}
+	return cs
+}
@@ -0,0 +1,77 @@
+package block_stm
+
+import (
+	"context"
+	"testing"
+
+	storetypes "cosmossdk.io/store/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestChangeSets(t *testing.T) {
+	objKey := storetypes.NewObjectStoreKey("obj")
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1, objKey: 2}
+	storage := NewMultiMemDB(stores)
+	mv := NewMVMemory(3, stores, storage, nil)
+
+	mview := mv.View(0)
+	mview.GetKVStore(StoreKeyBank).Set(Key("b"), []byte("1"))
+	mview.GetKVStore(StoreKeyAuth).Set(Key("a"), []byte("1"))
+	mview.GetObjKVStore(objKey).Set(Key("o"), 1)
+	require.True(t, mv.Record(TxnVersion{0, 0}, mview))
+
+	mview = mv.View(2)
+	mview.GetKVStore(StoreKeyAuth).Delete(Key("a"))
+	mview.GetObjKVStore(objKey).Delete(Key("o"))
+	require.True(t, mv.Record(TxnVersion{2, 0}, mview))
+
+	// re-execution of txn 2 writes different locations
+	mview = mv.View(2)
+	mview.GetKVStore(StoreKeyBank).Set(Key("c"), []byte("2"))
+	require.True(t, mv.Record(TxnVersion{2, 1}, mview))
+
+	require.Equal(t, []ChangeSet{
+		{Stores: []StoreChangeSet{
+			{Store: StoreKeyAuth, Pairs: []GKVPair[any]{{Key("a"), []byte("1")}}},
+			{Store: StoreKeyBank, Pairs: []GKVPair[any]{{Key("b"), []byte("1")}}},
+			{Store: objKey, Pairs: []GKVPair[any]{{Key("o"), 1}}},
+		}},
+		{},
+		{Stores: []StoreChangeSet{
+			{Store: StoreKeyBank, Pairs: []GKVPair[any]{{Key("c"), []byte("2")}}},
+		}},
+	}, mv.ChangeSets())
+}
+
+func TestExecuteBlockWithChangeSets(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	blk := testBlock(100, 3)
+	storage := NewMultiMemDB(stores)
+
+	var changeSets []ChangeSet
+	require.NoError(t, ExecuteBlock(
+		context.Background(), blk.Size(), stores, storage, 10, blk.ExecuteTx,
+		WithChangeSets(func(cs []ChangeSet) {
+			changeSets = cs
+		}),
+	))
+	require.Len(t, changeSets, blk.Size())
+
+	// replaying the change sets in order reproduces the final state
+	replay := NewMultiMemDB(stores)
+	for _, cs := range changeSets {
+		for _, store := range cs.Stores {
+			kv := replay.GetKVStore(store.Store)
+			for _, pair := range store.Pairs {
+				if pair.Value == nil {
+					kv.Delete(pair.Key)
+				} else {
+					kv.Set(pair.Key, pair.Value.([]byte))
+				}
+			}
+		}
+	}
+	for store := range stores {
+		require.True(t, StoreEqual(storage.GetKVStore(store), replay.GetKVStore(store)))
+	}
+}
@@ -0,0 +1,166 @@
+// Package bench contains the benchmark loop shared by the command line tools.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"time"
+
+	storetypes "cosmossdk.io/store/types"
+
+	block_stm "github.com/crypto-org-chain/go-block-stm"
+)
+
+// Result is the averaged metrics of the runs of one configuration.
+type Result struct {
+	Workload  string `json:"workload"`
+	Mode      string `json:"mode"`
+	Executors int    `json:"executors"`
+	Txs       int    `json:"txs"`
+	Runs      int    `json:"runs"`
+
+	AvgNs      int64   `json:"avg_ns"`
+	Throughput float64 `json:"throughput_tps"`
+
+	// scheduler metrics per run, zero in sequential mode
+	Executed  float64 `json:"executed"`
+	Validated float64 `json:"validated"`
+	Aborted   float64 `json:"aborted"`
+	Suspended float64 `json:"suspended"`
+	AbortRate float64 `json:"abort_rate"`
+
+	AllocsPerRun uint64 `json:"allocs_per_run"`
+	BytesPerRun  uint64 `json:"bytes_per_run"`
+}
+
+// Block is the source of the benchmarked transactions.
+type Block struct {
+	Name       string
+	Size       int
+	Stores     map[storetypes.StoreKey]int
+	NewStorage func() block_stm.MultiStore
+	ExecuteTx  block_stm.TxExecutor
+}
+
+// TraceBlock loads a block trace recorded with `block_stm.WithTrace`, the transactions replay the recorded reads and
+// writes.
+func TraceBlock(path string) (*Block, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	trace, err := block_stm.ReadBlockTrace(f)
+	if err != nil {
+		return nil, fmt.Errorf("read trace: %w", err)
+	}
+	replay := block_stm.NewReplay(trace)
+	return &Block{
+		Name:   "trace-" + path,
+		Size:   replay.Size(),
+		Stores: replay.Stores(),
+		NewStorage: func() block_stm.MultiStore {
+			return replay.NewStorage()
+		},
+		ExecuteTx: replay.ExecuteTx,
+	}, nil
+}
+
+// Run runs the block sequentially, then with each of the executor counts, `runs` times for each configuration.
+func Run(b *Block, executors []int, runs int) ([]Result, error) {
+	if runs <= 0 {
+		return nil, fmt.Errorf("invalid number of runs: %d", runs)
+	}
+
+	sequential, err := benchSequential(b, runs)
+	if err != nil {
+		return nil, err
+	}
+	results := []Result{sequential}
+	for _, n := range executors {
+		r, err := benchParallel(b, n, runs)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+func benchSequential(b *Block, runs int) (Result, error) {
+	return measure(b, "sequential", 0, runs, func(storage block_stm.MultiStore) (block_stm.SchedulerStats, error) {
+		for txn := 0; txn < b.Size; txn++ {
+			b.ExecuteTx(block_stm.TxnIndex(txn), storage)
+		}
+		return block_stm.SchedulerStats{}, nil
+	})
+}
+
+func benchParallel(b *Block, executors, runs int) (Result, error) {
+	return measure(b, "executors-"+strconv.Itoa(executors), executors, runs,
+		func(storage block_stm.MultiStore) (stats block_stm.SchedulerStats, err error) {
+			err = block_stm.ExecuteBlock(
+				context.Background(), b.Size, b.Stores, storage, executors, b.ExecuteTx,
+				block_stm.WithStats(func(s block_stm.SchedulerStats) { stats = s }),
+			)
+			return
+		})
+}
+
+// measure runs the block `runs` times on fresh storages, the storage creation is excluded from the metrics, it stops
+// at the first failed run and returns the error.
+func measure(
+	b *Block, mode string, executors, runs int,
+	fn func(block_stm.MultiStore) (block_stm.SchedulerStats, error),
+) (Result, error) {
+	var (
+		elapsed        time.Duration
+		total          block_stm.SchedulerStats
+		allocs, nbytes uint64
+		before, after  runtime.MemStats
+	)
+	for i := 0; i < runs; i++ {
+		storage := b.NewStorage()
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		stats, err := fn(storage)
+		elapsed += time.Since(start)
+		runtime.ReadMemStats(&after)
+		if err != nil {
+			return Result{}, fmt.Errorf("%s run %d: %w", mode, i, err)
+		}
+
+		allocs += after.Mallocs - before.Mallocs
+		nbytes += after.TotalAlloc - before.TotalAlloc
+		total.Executed += stats.Executed
+		total.Validated += stats.Validated
+		total.Aborted += stats.Aborted
+		total.Suspended += stats.Suspended
+	}
+
+	avg := elapsed / time.Duration(runs)
+	r := Result{
+		Workload:     b.Name,
+		Mode:         mode,
+		Executors:    executors,
+		Txs:          b.Size,
+		Runs:         runs,
+		AvgNs:        avg.Nanoseconds(),
+		Throughput:   float64(b.Size) / avg.Seconds(),
+		Executed:     float64(total.Executed) / float64(runs),
+		Validated:    float64(total.Validated) / float64(runs),
+		Aborted:      float64(total.Aborted) / float64(runs),
+		Suspended:    float64(total.Suspended) / float64(runs),
+		AllocsPerRun: allocs / uint64(runs),
+		BytesPerRun:  nbytes / uint64(runs),
+	}
+	if total.Executed > 0 {
+		r.AbortRate = float64(total.Aborted) / float64(total.Executed)
+	}
+	return r, nil
+}
@@ -0,0 +1,21 @@
+// Package cliutil contains the flag parsing helpers shared by the command line tools.
+package cliutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseExecutors parses a comma separated list of positive executor counts, e.g. "1,4,8".
+func ParseExecutors(s string) ([]int, error) {
+	var counts []int
+	for _, part := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid executor count: %q", part)
+		}
+		counts = append(counts, n)
+	}
+	return counts, nil
+}
@@ -0,0 +1,86 @@
+// stm-analyze reports the conflicts of a block trace recorded with `block_stm.WithTrace`: the theoretical parallelism,
+// the hottest conflicting keys of each store, and the expected speedup with different numbers of executors.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	block_stm "github.com/crypto-org-chain/go-block-stm"
+	"github.com/crypto-org-chain/go-block-stm/cmd/internal/cliutil"
+)
+
+func main() {
+	tracePath := flag.String("trace", "", "path of the block trace file")
+	top := flag.Int("top", 10, "number of the hottest keys to report for each store")
+	executors := flag.String("executors", "1,2,4,8,16,32", "comma separated list of executor counts")
+	flag.Parse()
+
+	if err := run(*tracePath, *top, *executors); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(tracePath string, top int, executors string) error {
+	if tracePath == "" {
+		return fmt.Errorf("missing -trace")
+	}
+	if top < 0 {
+		return fmt.Errorf("invalid number of hot keys: %d", top)
+	}
+	counts, err := cliutil.ParseExecutors(executors)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(tracePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	trace, err := block_stm.ReadBlockTrace(f)
+	if err != nil {
+		return fmt.Errorf("read trace: %w", err)
+	}
+
+	a, err := block_stm.AnalyzeTrace(trace, top)
+	if err != nil {
+		return fmt.Errorf("analyze trace: %w", err)
+	}
+	fmt.Printf("txs:           %d\n", a.Txs)
+	fmt.Printf("re-executions: %d\n", a.ReExecutions)
+	fmt.Printf("critical path: %d\n", a.CriticalPath)
+	fmt.Printf("parallelism:   %.2f\n", a.Parallelism())
+
+	fmt.Println("\nexpected speedup:")
+	for _, n := range counts {
+		fmt.Printf("  %4d executors: %.2fx\n", n, a.Speedup(n))
+	}
+
+	stores := make([]string, 0, len(a.HotKeys))
+	for name := range a.HotKeys {
+		stores = append(stores, name)
+	}
+	sort.Strings(stores)
+	for _, name := range stores {
+		fmt.Printf("\nhottest keys of store %q:\n", name)
+		for _, hot := range a.HotKeys[name] {
+			fmt.Printf("  %6d  %s\n", hot.Conflicts, formatKey(hot.Key))
+		}
+	}
+	return nil
+}
+
+// formatKey prints the printable keys as is, otherwise in hex.
+func formatKey(key []byte) string {
+	for _, b := range key {
+		if b < 0x20 || b > 0x7e {
+			return fmt.Sprintf("%X", key)
+		}
+	}
+	return string(key)
+}
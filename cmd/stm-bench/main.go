@@ -0,0 +1,142 @@
+// stm-bench runs a workload, generated by `block_stm.NewWorkload` or replayed from a block trace, sequentially and
+// with different numbers of executors, and emits the results as JSON or CSV, so they can be compared across machines
+// and commits.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	block_stm "github.com/crypto-org-chain/go-block-stm"
+	"github.com/crypto-org-chain/go-block-stm/cmd/internal/bench"
+	"github.com/crypto-org-chain/go-block-stm/cmd/internal/cliutil"
+)
+
+func main() {
+	spec := block_stm.DefaultWorkloadSpec()
+	tracePath := flag.String("trace", "", "path of a block trace file, replayed instead of the generated workload")
+	distribution := flag.String("distribution", "uniform", "key distribution of the generated workload: uniform, zipf or hotspot")
+	flag.Int64Var(&spec.Seed, "seed", spec.Seed, "seed of the generated workload")
+	flag.IntVar(&spec.Txs, "txs", spec.Txs, "number of transactions")
+	flag.IntVar(&spec.OpsPerTx, "ops", spec.OpsPerTx, "number of operations per transaction")
+	flag.IntVar(&spec.Stores, "stores", spec.Stores, "number of kv stores")
+	flag.IntVar(&spec.ObjectStores, "object-stores", spec.ObjectStores, "number of object stores")
+	flag.IntVar(&spec.Keys, "keys", spec.Keys, "size of the key space of each store")
+	flag.Float64Var(&spec.ZipfS, "zipf-s", spec.ZipfS, "exponent of the zipf distribution")
+	flag.IntVar(&spec.HotspotKeys, "hotspot-keys", spec.HotspotKeys, "number of the hot keys")
+	flag.Float64Var(&spec.HotspotRatio, "hotspot-ratio", spec.HotspotRatio, "probability to access the hot keys")
+	flag.Float64Var(&spec.ReadRatio, "read-ratio", spec.ReadRatio, "probability of an operation to be a read")
+	flag.Float64Var(&spec.DeleteRatio, "delete-ratio", spec.DeleteRatio, "probability of a write to be a delete")
+	flag.Float64Var(&spec.IteratorRatio, "iterator-ratio", spec.IteratorRatio, "probability of an operation to be an iteration")
+	flag.IntVar(&spec.IteratorRange, "iterator-range", spec.IteratorRange, "max number of keys of an iteration")
+	flag.Float64Var(&spec.ObjectRatio, "object-ratio", spec.ObjectRatio, "probability of an operation to be on the object stores")
+	flag.IntVar(&spec.CPUCost, "cpu-cost", spec.CPUCost, "number of sha256 rounds per transaction")
+	executors := flag.String("executors", "1,2,4,8,16", "comma separated list of executor counts")
+	runs := flag.Int("runs", 3, "number of runs for each configuration")
+	format := flag.String("format", "json", "output format: json or csv")
+	output := flag.String("o", "", "output file, default to stdout")
+	flag.Parse()
+
+	if err := run(spec, *distribution, *tracePath, *executors, *runs, *format, *output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(spec block_stm.WorkloadSpec, distribution, tracePath, executors string, runs int, format, output string) error {
+	counts, err := cliutil.ParseExecutors(executors)
+	if err != nil {
+		return err
+	}
+	if format != "json" && format != "csv" {
+		return fmt.Errorf("invalid format: %q", format)
+	}
+
+	var b *bench.Block
+	if tracePath != "" {
+		b, err = bench.TraceBlock(tracePath)
+	} else {
+		b, err = workloadBlock(spec, distribution)
+	}
+	if err != nil {
+		return err
+	}
+
+	results, err := bench.Run(b, counts, runs)
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if format == "csv" {
+		return writeCSV(w, results)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+func workloadBlock(spec block_stm.WorkloadSpec, distribution string) (*bench.Block, error) {
+	switch distribution {
+	case "uniform":
+		spec.Distribution = block_stm.DistributionUniform
+	case "zipf":
+		spec.Distribution = block_stm.DistributionZipfian
+	case "hotspot":
+		spec.Distribution = block_stm.DistributionHotspot
+	default:
+		return nil, fmt.Errorf("invalid distribution: %q", distribution)
+	}
+	w, err := block_stm.NewWorkload(spec)
+	if err != nil {
+		return nil, err
+	}
+	return &bench.Block{
+		Name:   "workload-" + distribution,
+		Size:   w.Block.Size(),
+		Stores: w.Stores,
+		NewStorage: func() block_stm.MultiStore {
+			return block_stm.NewMultiMemDB(w.Stores)
+		},
+		ExecuteTx: w.Block.ExecuteTx,
+	}, nil
+}
+
+func writeCSV(w io.Writer, results []bench.Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{
+		"workload", "mode", "executors", "txs", "runs", "avg_ns", "throughput_tps",
+		"executed", "validated", "aborted", "suspended", "abort_rate", "allocs_per_run", "bytes_per_run",
+	}); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if err := cw.Write([]string{
+			r.Workload, r.Mode, strconv.Itoa(r.Executors), strconv.Itoa(r.Txs), strconv.Itoa(r.Runs),
+			strconv.FormatInt(r.AvgNs, 10), formatFloat(r.Throughput),
+			formatFloat(r.Executed), formatFloat(r.Validated), formatFloat(r.Aborted), formatFloat(r.Suspended),
+			formatFloat(r.AbortRate), strconv.FormatUint(r.AllocsPerRun, 10), strconv.FormatUint(r.BytesPerRun, 10),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
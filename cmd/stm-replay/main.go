@@ -0,0 +1,50 @@
+// stm-replay replays a block trace recorded with `block_stm.WithTrace` through `ExecuteBlock`, to benchmark the engine
+// against real blocks without the application.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/crypto-org-chain/go-block-stm/cmd/internal/bench"
+	"github.com/crypto-org-chain/go-block-stm/cmd/internal/cliutil"
+)
+
+func main() {
+	tracePath := flag.String("trace", "", "path of the block trace file")
+	executors := flag.String("executors", "1,2,4,8,16", "comma separated list of executor counts")
+	runs := flag.Int("runs", 3, "number of runs for each executor count")
+	flag.Parse()
+
+	if err := run(*tracePath, *executors, *runs); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(tracePath, executors string, runs int) error {
+	if tracePath == "" {
+		return fmt.Errorf("missing -trace")
+	}
+	counts, err := cliutil.ParseExecutors(executors)
+	if err != nil {
+		return err
+	}
+
+	b, err := bench.TraceBlock(tracePath)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("block: %d txs, %d stores\n", b.Size, len(b.Stores))
+
+	results, err := bench.Run(b, counts, runs)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		fmt.Printf("%-12s avg %-12s %.0f tx/s\n", r.Mode, time.Duration(r.AvgNs), r.Throughput)
+	}
+	return nil
+}
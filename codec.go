@@ -0,0 +1,371 @@
+package block_stm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// CodecVersion is the version byte prefixed to the binary encoding, bumped on incompatible format changes.
+const CodecVersion byte = 1
+
+// MaxStoreIndex bounds the decoded store indexes, the consumers still need to check them against the actual stores.
+const MaxStoreIndex = math.MaxInt32
+
+var (
+	ErrCodecTruncated = errors.New("codec: unexpected end of input")
+	ErrCodecTrailing  = errors.New("codec: trailing bytes after the value")
+)
+
+// Codec encodes the access traces into deterministic bytes, the maps are encoded in the order of store index.
+type Codec interface {
+	EncodeReadSet(*ReadSet) []byte
+	DecodeReadSet([]byte) (*ReadSet, error)
+	EncodeIteratorDescriptor(*IteratorDescriptor) []byte
+	DecodeIteratorDescriptor([]byte) (*IteratorDescriptor, error)
+	EncodeMultiReadSet(MultiReadSet) []byte
+	DecodeMultiReadSet([]byte) (MultiReadSet, error)
+	EncodeMultiLocations(MultiLocations) []byte
+	DecodeMultiLocations([]byte) (MultiLocations, error)
+}
+
+var (
+	// BinaryCodec is the compact native encoding, prefixed with `CodecVersion`.
+	BinaryCodec Codec = binaryCodec{}
+	// ProtoCodec is compatible with the protobuf messages documented in `codec_proto.go`.
+	ProtoCodec Codec = protoCodec{}
+)
+
+// binaryCodec encodes the integers as varints, and the byte slices as `uvarint(len+1) || bytes`, zero means `nil`,
+// so `nil` and empty keys are distinguished, e.g. the unbounded iterator range.
+type binaryCodec struct{}
+
+func (binaryCodec) EncodeReadSet(rs *ReadSet) []byte {
+	return appendReadSet([]byte{CodecVersion}, rs)
+}
+
+func (binaryCodec) DecodeReadSet(bz []byte) (*ReadSet, error) {
+	d, err := newBinaryDecoder(bz)
+	if err != nil {
+		return nil, err
+	}
+	rs := d.readSet()
+	return rs, d.finish()
+}
+
+func (binaryCodec) EncodeIteratorDescriptor(desc *IteratorDescriptor) []byte {
+	return appendIteratorDescriptor([]byte{CodecVersion}, desc)
+}
+
+func (binaryCodec) DecodeIteratorDescriptor(bz []byte) (*IteratorDescriptor, error) {
+	d, err := newBinaryDecoder(bz)
+	if err != nil {
+		return nil, err
+	}
+	desc := d.iteratorDescriptor()
+	return &desc, d.finish()
+}
+
+func (binaryCodec) EncodeMultiReadSet(rs MultiReadSet) []byte {
+	return appendMultiReadSet([]byte{CodecVersion}, rs)
+}
+
+func (binaryCodec) DecodeMultiReadSet(bz []byte) (MultiReadSet, error) {
+	d, err := newBinaryDecoder(bz)
+	if err != nil {
+		return nil, err
+	}
+	rs := d.multiReadSet()
+	return rs, d.finish()
+}
+
+func (binaryCodec) EncodeMultiLocations(locations MultiLocations) []byte {
+	return appendMultiLocations([]byte{CodecVersion}, locations)
+}
+
+func (binaryCodec) DecodeMultiLocations(bz []byte) (MultiLocations, error) {
+	d, err := newBinaryDecoder(bz)
+	if err != nil {
+		return nil, err
+	}
+	locations := d.multiLocations()
+	return locations, d.finish()
+}
+
+func appendMultiReadSet(buf []byte, rs MultiReadSet) []byte {
+	stores := sortedStoreIndexes(rs)
+	buf = binary.AppendUvarint(buf, uint64(len(stores)))
+	for _, store := range stores {
+		buf = binary.AppendUvarint(buf, uint64(store))
+		buf = appendReadSet(buf, rs[store])
+	}
+	return buf
+}
+
+func appendMultiLocations(buf []byte, locations MultiLocations) []byte {
+	stores := sortedStoreIndexes(locations)
+	buf = binary.AppendUvarint(buf, uint64(len(stores)))
+	for _, store := range stores {
+		buf = binary.AppendUvarint(buf, uint64(store))
+		buf = binary.AppendUvarint(buf, uint64(len(locations[store])))
+		for _, key := range locations[store] {
+			buf = appendNullableBytes(buf, key)
+		}
+	}
+	return buf
+}
+
+func appendReadSet(buf []byte, rs *ReadSet) []byte {
+	if rs == nil {
+		rs = &ReadSet{}
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(rs.Reads)))
+	for _, desc := range rs.Reads {
+		buf = appendReadDescriptor(buf, desc)
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(rs.Iterators)))
+	for i := range rs.Iterators {
+		buf = appendIteratorDescriptor(buf, &rs.Iterators[i])
+	}
+	return buf
+}
+
+func appendReadDescriptor(buf []byte, desc ReadDescriptor) []byte {
+	buf = appendNullableBytes(buf, desc.Key)
+	buf = binary.AppendVarint(buf, int64(desc.Version.Index))
+	return binary.AppendUvarint(buf, uint64(desc.Version.Incarnation))
+}
+
+func appendIteratorDescriptor(buf []byte, desc *IteratorDescriptor) []byte {
+	buf = appendNullableBytes(buf, desc.Start)
+	buf = appendNullableBytes(buf, desc.End)
+	if desc.Ascending {
+		buf = append(buf, 1)
+	} else {
+		buf = append(buf, 0)
+	}
+	buf = appendNullableBytes(buf, desc.Stop)
+	buf = binary.AppendUvarint(buf, uint64(len(desc.Reads)))
+	for _, read := range desc.Reads {
+		buf = appendReadDescriptor(buf, read)
+	}
+	return buf
+}
+
+func appendNullableBytes(buf []byte, bz []byte) []byte {
+	if bz == nil {
+		return binary.AppendUvarint(buf, 0)
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(bz))+1)
+	return append(buf, bz...)
+}
+
+// binaryDecoder records the first error, the following reads return zero values.
+type binaryDecoder struct {
+	bz  []byte
+	err error
+}
+
+func newBinaryDecoder(bz []byte) (*binaryDecoder, error) {
+	if len(bz) == 0 {
+		return nil, ErrCodecTruncated
+	}
+	if bz[0] != CodecVersion {
+		return nil, fmt.Errorf("codec: unsupported version %d", bz[0])
+	}
+	return &binaryDecoder{bz: bz[1:]}, nil
+}
+
+func (d *binaryDecoder) finish() error {
+	if d.err == nil && len(d.bz) > 0 {
+		d.err = ErrCodecTrailing
+	}
+	return d.err
+}
+
+func (d *binaryDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.bz)
+	if n <= 0 {
+		d.err = ErrCodecTruncated
+		return 0
+	}
+	d.bz = d.bz[n:]
+	return v
+}
+
+func (d *binaryDecoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.bz)
+	if n <= 0 {
+		d.err = ErrCodecTruncated
+		return 0
+	}
+	d.bz = d.bz[n:]
+	return v
+}
+
+// count reads a length prefix, which can't exceed the remaining bytes, since each element takes at least one byte.
+func (d *binaryDecoder) count() int {
+	n := d.uvarint()
+	if n > uint64(len(d.bz)) {
+		if d.err == nil {
+			d.err = ErrCodecTruncated
+		}
+		return 0
+	}
+	return int(n)
+}
+
+func (d *binaryDecoder) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+	if len(d.bz) == 0 {
+		d.err = ErrCodecTruncated
+		return 0
+	}
+	b := d.bz[0]
+	d.bz = d.bz[1:]
+	return b
+}
+
+func (d *binaryDecoder) bytes() []byte {
+	n := d.uvarint()
+	if n == 0 {
+		return nil
+	}
+	n--
+	if n > uint64(len(d.bz)) {
+		if d.err == nil {
+			d.err = ErrCodecTruncated
+		}
+		return nil
+	}
+	bz := make([]byte, n)
+	copy(bz, d.bz)
+	d.bz = d.bz[n:]
+	return bz
+}
+
+func (d *binaryDecoder) readDescriptor() ReadDescriptor {
+	return ReadDescriptor{
+		Key: d.bytes(),
+		Version: TxnVersion{
+			Index:       TxnIndex(d.varint()),
+			Incarnation: Incarnation(d.uvarint()),
+		},
+	}
+}
+
+func (d *binaryDecoder) readDescriptors() []ReadDescriptor {
+	n := d.count()
+	if n == 0 {
+		return nil
+	}
+	reads := make([]ReadDescriptor, n)
+	for i := range reads {
+		reads[i] = d.readDescriptor()
+	}
+	return reads
+}
+
+func (d *binaryDecoder) iteratorDescriptor() IteratorDescriptor {
+	var desc IteratorDescriptor
+	desc.Start = d.bytes()
+	desc.End = d.bytes()
+	desc.Ascending = d.byte() != 0
+	desc.Stop = d.bytes()
+	desc.Reads = d.readDescriptors()
+	return desc
+}
+
+func (d *binaryDecoder) readSet() *ReadSet {
+	rs := &ReadSet{Reads: d.readDescriptors()}
+	n := d.count()
+	if n > 0 {
+		rs.Iterators = make([]IteratorDescriptor, n)
+		for i := range rs.Iterators {
+			rs.Iterators[i] = d.iteratorDescriptor()
+		}
+	}
+	return rs
+}
+
+func (d *binaryDecoder) multiReadSet() MultiReadSet {
+	n := d.count()
+	rs := make(MultiReadSet, n)
+	for i := 0; i < n && d.err == nil; i++ {
+		store := storeIndex(d, rs)
+		rs[store] = d.readSet()
+	}
+	return rs
+}
+
+func (d *binaryDecoder) multiLocations() MultiLocations {
+	n := d.count()
+	locations := make(MultiLocations, n)
+	for i := 0; i < n && d.err == nil; i++ {
+		store := storeIndex(d, locations)
+		var keys Locations
+		if n := d.count(); n > 0 {
+			keys = make(Locations, n)
+			for j := range keys {
+				keys[j] = d.bytes()
+			}
+		}
+		if d.err == nil {
+			d.err = checkLocations(store, keys)
+		}
+		locations[store] = keys
+	}
+	return locations
+}
+
+// storeIndex reads a store index which must be in range and not seen before.
+func storeIndex[T any](d *binaryDecoder, seen map[int]T) int {
+	v := d.uvarint()
+	if d.err != nil {
+		return 0
+	}
+	store, err := checkStoreIndex(v, seen)
+	if err != nil {
+		d.err = err
+	}
+	return store
+}
+
+func sortedStoreIndexes[T any](m map[int]T) []int {
+	stores := make([]int, 0, len(m))
+	for store := range m {
+		stores = append(stores, store)
+	}
+	sort.Ints(stores)
+	return stores
+}
+
+// checkStoreIndex checks the decoded store index is in range and not duplicated.
+func checkStoreIndex[T any](v uint64, seen map[int]T) (int, error) {
+	if v > MaxStoreIndex {
+		return 0, fmt.Errorf("codec: store index %d is out of range", v)
+	}
+	store := int(v)
+	if _, ok := seen[store]; ok {
+		return 0, fmt.Errorf("codec: duplicated store index %d", store)
+	}
+	return store, nil
+}
+
+// checkLocations checks the decoded keys are strictly sorted, as required by `Locations`.
+func checkLocations(store int, keys Locations) error {
+	if err := validateSortedKeys(keys); err != nil {
+		return fmt.Errorf("codec: store %d: %w", store, err)
+	}
+	return nil
+}
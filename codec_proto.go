@@ -0,0 +1,345 @@
+package block_stm
+
+import (
+	"errors"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// protoCodec encodes the values as the following protobuf messages, the fields with default values are omitted.
+// The `optional bytes` fields have explicit presence, they are omitted if `nil`, and emitted even if empty, so `nil`
+// and empty keys are distinguished, e.g. the unbounded iterator range, the store indexes must be unique, and the keys
+// of the locations must be strictly sorted.
+//
+//	message ReadDescriptor {
+//	  optional bytes key = 1;
+//	  sint64 index = 2;
+//	  uint64 incarnation = 3;
+//	}
+//
+//	message IteratorDescriptor {
+//	  optional bytes start = 1;
+//	  optional bytes end = 2;
+//	  bool ascending = 3;
+//	  optional bytes stop = 4;
+//	  repeated ReadDescriptor reads = 5;
+//	}
+//
+//	message ReadSet {
+//	  repeated ReadDescriptor reads = 1;
+//	  repeated IteratorDescriptor iterators = 2;
+//	}
+//
+//	message MultiReadSet {
+//	  message Entry {
+//	    uint64 store = 1;
+//	    ReadSet read_set = 2;
+//	  }
+//	  repeated Entry stores = 1;
+//	}
+//
+//	message MultiLocations {
+//	  message Entry {
+//	    uint64 store = 1;
+//	    repeated bytes keys = 2;
+//	  }
+//	  repeated Entry stores = 1;
+//	}
+type protoCodec struct{}
+
+var errProtoMalformed = errors.New("codec: malformed protobuf message")
+
+func (protoCodec) EncodeReadSet(rs *ReadSet) []byte {
+	return protoAppendReadSet(nil, rs)
+}
+
+func (protoCodec) DecodeReadSet(bz []byte) (*ReadSet, error) {
+	return protoDecodeReadSet(bz)
+}
+
+func (protoCodec) EncodeIteratorDescriptor(desc *IteratorDescriptor) []byte {
+	return protoAppendIteratorDescriptor(nil, desc)
+}
+
+func (protoCodec) DecodeIteratorDescriptor(bz []byte) (*IteratorDescriptor, error) {
+	desc, err := protoDecodeIteratorDescriptor(bz)
+	if err != nil {
+		return nil, err
+	}
+	return &desc, nil
+}
+
+func (protoCodec) EncodeMultiReadSet(rs MultiReadSet) []byte {
+	var buf []byte
+	for _, store := range sortedStoreIndexes(rs) {
+		var entry []byte
+		entry = protoAppendUint(entry, 1, uint64(store))
+		entry = protoAppendMessage(entry, 2, protoAppendReadSet(nil, rs[store]))
+		buf = protoAppendMessage(buf, 1, entry)
+	}
+	return buf
+}
+
+func (protoCodec) DecodeMultiReadSet(bz []byte) (MultiReadSet, error) {
+	rs := make(MultiReadSet)
+	err := protoScan(bz, func(num protowire.Number, typ protowire.Type, field []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+
+		var (
+			store   uint64
+			readSet = &ReadSet{}
+		)
+		err := protoScan(field, func(num protowire.Number, typ protowire.Type, field []byte) (err error) {
+			switch {
+			case num == 1 && typ == protowire.VarintType:
+				store, err = protoUint(field)
+			case num == 2 && typ == protowire.BytesType:
+				readSet, err = protoDecodeReadSet(field)
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		i, err := checkStoreIndex(store, rs)
+		if err != nil {
+			return err
+		}
+		rs[i] = readSet
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+func (protoCodec) EncodeMultiLocations(locations MultiLocations) []byte {
+	var buf []byte
+	for _, store := range sortedStoreIndexes(locations) {
+		var entry []byte
+		entry = protoAppendUint(entry, 1, uint64(store))
+		for _, key := range locations[store] {
+			entry = protoAppendBytes(entry, 2, key)
+		}
+		buf = protoAppendMessage(buf, 1, entry)
+	}
+	return buf
+}
+
+func (protoCodec) DecodeMultiLocations(bz []byte) (MultiLocations, error) {
+	locations := make(MultiLocations)
+	err := protoScan(bz, func(num protowire.Number, typ protowire.Type, field []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+
+		var (
+			store uint64
+			keys  Locations
+		)
+		err := protoScan(field, func(num protowire.Number, typ protowire.Type, field []byte) (err error) {
+			switch {
+			case num == 1 && typ == protowire.VarintType:
+				store, err = protoUint(field)
+			case num == 2 && typ == protowire.BytesType:
+				keys = append(keys, protoBytes(field))
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		i, err := checkStoreIndex(store, locations)
+		if err != nil {
+			return err
+		}
+		if err := checkLocations(i, keys); err != nil {
+			return err
+		}
+		locations[i] = keys
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return locations, nil
+}
+
+func protoAppendReadSet(buf []byte, rs *ReadSet) []byte {
+	if rs == nil {
+		return buf
+	}
+	for _, desc := range rs.Reads {
+		buf = protoAppendMessage(buf, 1, protoAppendReadDescriptor(nil, desc))
+	}
+	for i := range rs.Iterators {
+		buf = protoAppendMessage(buf, 2, protoAppendIteratorDescriptor(nil, &rs.Iterators[i]))
+	}
+	return buf
+}
+
+func protoAppendReadDescriptor(buf []byte, desc ReadDescriptor) []byte {
+	buf = protoAppendBytes(buf, 1, desc.Key)
+	if desc.Version.Index != 0 {
+		buf = protoAppendUint(buf, 2, protowire.EncodeZigZag(int64(desc.Version.Index)))
+	}
+	return protoAppendUint(buf, 3, uint64(desc.Version.Incarnation))
+}
+
+func protoAppendIteratorDescriptor(buf []byte, desc *IteratorDescriptor) []byte {
+	buf = protoAppendBytes(buf, 1, desc.Start)
+	buf = protoAppendBytes(buf, 2, desc.End)
+	if desc.Ascending {
+		buf = protoAppendUint(buf, 3, 1)
+	}
+	buf = protoAppendBytes(buf, 4, desc.Stop)
+	for _, read := range desc.Reads {
+		buf = protoAppendMessage(buf, 5, protoAppendReadDescriptor(nil, read))
+	}
+	return buf
+}
+
+func protoDecodeReadSet(bz []byte) (*ReadSet, error) {
+	rs := &ReadSet{}
+	err := protoScan(bz, func(num protowire.Number, typ protowire.Type, field []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			desc, err := protoDecodeReadDescriptor(field)
+			if err != nil {
+				return err
+			}
+			rs.Reads = append(rs.Reads, desc)
+		case 2:
+			desc, err := protoDecodeIteratorDescriptor(field)
+			if err != nil {
+				return err
+			}
+			rs.Iterators = append(rs.Iterators, desc)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+func protoDecodeReadDescriptor(bz []byte) (desc ReadDescriptor, err error) {
+	err = protoScan(bz, func(num protowire.Number, typ protowire.Type, field []byte) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			desc.Key = protoBytes(field)
+		case num == 2 && typ == protowire.VarintType:
+			v, err := protoUint(field)
+			if err != nil {
+				return err
+			}
+			desc.Version.Index = TxnIndex(protowire.DecodeZigZag(v))
+		case num == 3 && typ == protowire.VarintType:
+			v, err := protoUint(field)
+			if err != nil {
+				return err
+			}
+			desc.Version.Incarnation = Incarnation(v)
+		}
+		return nil
+	})
+	return
+}
+
+func protoDecodeIteratorDescriptor(bz []byte) (desc IteratorDescriptor, err error) {
+	err = protoScan(bz, func(num protowire.Number, typ protowire.Type, field []byte) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			desc.Start = protoBytes(field)
+		case num == 2 && typ == protowire.BytesType:
+			desc.End = protoBytes(field)
+		case num == 3 && typ == protowire.VarintType:
+			v, err := protoUint(field)
+			if err != nil {
+				return err
+			}
+			desc.Ascending = v != 0
+		case num == 4 && typ == protowire.BytesType:
+			desc.Stop = protoBytes(field)
+		case num == 5 && typ == protowire.BytesType:
+			read, err := protoDecodeReadDescriptor(field)
+			if err != nil {
+				return err
+			}
+			desc.Reads = append(desc.Reads, read)
+		}
+		return nil
+	})
+	return
+}
+
+// protoAppendBytes omits the `nil` value.
+func protoAppendBytes(buf []byte, num protowire.Number, bz []byte) []byte {
+	if bz == nil {
+		return buf
+	}
+	buf = protowire.AppendTag(buf, num, protowire.BytesType)
+	return protowire.AppendBytes(buf, bz)
+}
+
+// protoAppendMessage always emits the field, even if the message is empty.
+func protoAppendMessage(buf []byte, num protowire.Number, msg []byte) []byte {
+	buf = protowire.AppendTag(buf, num, protowire.BytesType)
+	return protowire.AppendBytes(buf, msg)
+}
+
+// protoAppendUint omits the zero value.
+func protoAppendUint(buf []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return buf
+	}
+	buf = protowire.AppendTag(buf, num, protowire.VarintType)
+	return protowire.AppendVarint(buf, v)
+}
+
+// protoScan calls the callback with the raw value of each field, unknown fields are skipped by the callback.
+func protoScan(bz []byte, cb func(protowire.Number, protowire.Type, []byte) error) error {
+	for len(bz) > 0 {
+		num, typ, n := protowire.ConsumeTag(bz)
+		if n < 0 {
+			return errProtoMalformed
+		}
+		bz = bz[n:]
+
+		n = protowire.ConsumeFieldValue(num, typ, bz)
+		if n < 0 {
+			return errProtoMalformed
+		}
+		field := bz[:n]
+		bz = bz[n:]
+
+		if typ == protowire.BytesType {
+			// strip the length prefix
+			field, _ = protowire.ConsumeBytes(field)
+		}
+		if err := cb(num, typ, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func protoUint(field []byte) (uint64, error) {
+	v, n := protowire.ConsumeVarint(field)
+	if n < 0 {
+		return 0, errProtoMalformed
+	}
+	return v, nil
+}
+
+// protoBytes copies the field, so the result don't alias the input buffer.
+func protoBytes(field []byte) []byte {
+	return append([]byte{}, field...)
+}
@@ -0,0 +1,309 @@
+package block_stm
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/test-go/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func sampleReadSet() *ReadSet {
+	return &ReadSet{
+		Reads: []ReadDescriptor{
+			{Key("a"), TxnVersion{0, 0}},
+			{Key("b"), InvalidTxnVersion},
+			{Key{}, TxnVersion{3, 2}},
+		},
+		Iterators: []IteratorDescriptor{
+			{
+				IteratorOptions: IteratorOptions{Start: nil, End: Key("z"), Ascending: true},
+				Stop:            Key("c"),
+				Reads: []ReadDescriptor{
+					{Key("a"), TxnVersion{1, 0}},
+					{Key("c"), InvalidTxnVersion},
+				},
+			},
+			{
+				IteratorOptions: IteratorOptions{Start: Key{}, End: nil, Ascending: false},
+			},
+		},
+	}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	for name, codec := range map[string]Codec{"binary": BinaryCodec, "proto": ProtoCodec} {
+		t.Run(name, func(t *testing.T) {
+			rs := sampleReadSet()
+			bz := codec.EncodeReadSet(rs)
+			decoded, err := codec.DecodeReadSet(bz)
+			require.NoError(t, err)
+			require.Equal(t, rs, decoded)
+
+			empty, err := codec.DecodeReadSet(codec.EncodeReadSet(&ReadSet{}))
+			require.NoError(t, err)
+			require.Equal(t, &ReadSet{}, empty)
+
+			desc := &rs.Iterators[0]
+			decodedDesc, err := codec.DecodeIteratorDescriptor(codec.EncodeIteratorDescriptor(desc))
+			require.NoError(t, err)
+			require.Equal(t, desc, decodedDesc)
+
+			mrs := MultiReadSet{0: rs, 3: {Reads: []ReadDescriptor{{Key("x"), TxnVersion{2, 1}}}}, 1: {}}
+			bz = codec.EncodeMultiReadSet(mrs)
+			decodedMRS, err := codec.DecodeMultiReadSet(bz)
+			require.NoError(t, err)
+			require.Equal(t, mrs, decodedMRS)
+
+			locations := MultiLocations{2: {Key("a"), Key("b")}, 0: {Key{}}, 5: nil}
+			bz = codec.EncodeMultiLocations(locations)
+			decodedLocations, err := codec.DecodeMultiLocations(bz)
+			require.NoError(t, err)
+			require.Equal(t, locations, decodedLocations)
+		})
+	}
+}
+
+func TestCodecDeterministic(t *testing.T) {
+	for name, codec := range map[string]Codec{"binary": BinaryCodec, "proto": ProtoCodec} {
+		t.Run(name, func(t *testing.T) {
+			mrs := MultiReadSet{}
+			locations := MultiLocations{}
+			for i := 0; i < 20; i++ {
+				mrs[i] = sampleReadSet()
+				locations[i] = Locations{Key("k")}
+			}
+
+			bz1 := codec.EncodeMultiReadSet(mrs)
+			bz2 := codec.EncodeMultiLocations(locations)
+			for i := 0; i < 10; i++ {
+				require.Equal(t, bz1, codec.EncodeMultiReadSet(mrs))
+				require.Equal(t, bz2, codec.EncodeMultiLocations(locations))
+			}
+		})
+	}
+}
+
+func TestBinaryCodecErrors(t *testing.T) {
+	bz := BinaryCodec.EncodeReadSet(sampleReadSet())
+	for i := 0; i < len(bz); i++ {
+		_, err := BinaryCodec.DecodeReadSet(bz[:i])
+		require.Error(t, err, "truncated at %d", i)
+	}
+
+	_, err := BinaryCodec.DecodeReadSet(append(bz, 0))
+	require.Equal(t, ErrCodecTrailing, err)
+
+	bz[0] = CodecVersion + 1
+	_, err = BinaryCodec.DecodeReadSet(bz)
+	require.Error(t, err)
+}
+
+func TestProtoCodecUnknownFields(t *testing.T) {
+	rs := sampleReadSet()
+	bz := ProtoCodec.EncodeReadSet(rs)
+	bz = protowire.AppendTag(bz, 100, protowire.VarintType)
+	bz = protowire.AppendVarint(bz, 1)
+	decoded, err := ProtoCodec.DecodeReadSet(bz)
+	require.NoError(t, err)
+	require.Equal(t, rs, decoded)
+
+	_, err = ProtoCodec.DecodeReadSet(bz[:len(bz)-1])
+	require.Error(t, err)
+}
+
+func TestCodecInvalidStores(t *testing.T) {
+	// hand-crafted encodings of the multi-locations entries: (store, keys)
+	type entry struct {
+		store uint64
+		keys  []Key
+	}
+	binaryLocations := func(entries ...entry) []byte {
+		buf := binary.AppendUvarint([]byte{CodecVersion}, uint64(len(entries)))
+		for _, e := range entries {
+			buf = binary.AppendUvarint(buf, e.store)
+			buf = binary.AppendUvarint(buf, uint64(len(e.keys)))
+			for _, key := range e.keys {
+				buf = appendNullableBytes(buf, key)
+			}
+		}
+		return buf
+	}
+	protoLocations := func(entries ...entry) []byte {
+		var buf []byte
+		for _, e := range entries {
+			entry := protowire.AppendTag(nil, 1, protowire.VarintType)
+			entry = protowire.AppendVarint(entry, e.store)
+			for _, key := range e.keys {
+				entry = protoAppendBytes(entry, 2, key)
+			}
+			buf = protoAppendMessage(buf, 1, entry)
+		}
+		return buf
+	}
+
+	testCases := []struct {
+		entries []entry
+		err     string
+	}{
+		{[]entry{{math.MaxUint64, nil}}, "codec: store index 18446744073709551615 is out of range"},
+		{[]entry{{MaxStoreIndex + 1, nil}}, "codec: store index 2147483648 is out of range"},
+		{[]entry{{1, []Key{Key("a")}}, {1, []Key{Key("b")}}}, "codec: duplicated store index 1"},
+		{[]entry{{0, []Key{Key("b"), Key("a")}}}, "codec: store 0: keys are not strictly sorted at 1: 62, 61"},
+		{[]entry{{0, []Key{Key("a"), Key("a")}}}, "codec: store 0: keys are not strictly sorted at 1: 61, 61"},
+	}
+	for _, tc := range testCases {
+		_, err := BinaryCodec.DecodeMultiLocations(binaryLocations(tc.entries...))
+		require.EqualError(t, err, tc.err)
+		_, err = ProtoCodec.DecodeMultiLocations(protoLocations(tc.entries...))
+		require.EqualError(t, err, tc.err)
+	}
+
+	// duplicated store of the multi read sets
+	mrs := MultiReadSet{1: {}}
+	bz := BinaryCodec.EncodeMultiReadSet(mrs)
+	bz[1] = 2 // count
+	bz = append(bz, bz[2:]...)
+	_, err := BinaryCodec.DecodeMultiReadSet(bz)
+	require.EqualError(t, err, "codec: duplicated store index 1")
+
+	bz = ProtoCodec.EncodeMultiReadSet(mrs)
+	_, err = ProtoCodec.DecodeMultiReadSet(append(bz, bz...))
+	require.EqualError(t, err, "codec: duplicated store index 1")
+}
+
+// newProtoSchema builds the messages documented in `codec_proto.go` with the standard protobuf implementation.
+func newProtoSchema(t *testing.T) protoreflect.FileDescriptor {
+	optionalBytes := func(name string, num int32, oneof int32) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:           proto.String(name),
+			Number:         proto.Int32(num),
+			Label:          descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:           descriptorpb.FieldDescriptorProto_TYPE_BYTES.Enum(),
+			OneofIndex:     proto.Int32(oneof),
+			Proto3Optional: proto.Bool(true),
+		}
+	}
+	field := func(name string, num int32, typ descriptorpb.FieldDescriptorProto_Type, repeated bool, msg string) *descriptorpb.FieldDescriptorProto {
+		label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		if repeated {
+			label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+		}
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(num),
+			Label:  label.Enum(),
+			Type:   typ.Enum(),
+		}
+		if msg != "" {
+			f.TypeName = proto.String(msg)
+		}
+		return f
+	}
+	oneofs := func(names ...string) (decls []*descriptorpb.OneofDescriptorProto) {
+		for _, name := range names {
+			decls = append(decls, &descriptorpb.OneofDescriptorProto{Name: proto.String(name)})
+		}
+		return
+	}
+
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("blockstm.proto"),
+		Package: proto.String("blockstm"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("ReadDescriptor"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					optionalBytes("key", 1, 0),
+					field("index", 2, descriptorpb.FieldDescriptorProto_TYPE_SINT64, false, ""),
+					field("incarnation", 3, descriptorpb.FieldDescriptorProto_TYPE_UINT64, false, ""),
+				},
+				OneofDecl: oneofs("_key"),
+			},
+			{
+				Name: proto.String("IteratorDescriptor"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					optionalBytes("start", 1, 0),
+					optionalBytes("end", 2, 1),
+					field("ascending", 3, descriptorpb.FieldDescriptorProto_TYPE_BOOL, false, ""),
+					optionalBytes("stop", 4, 2),
+					field("reads", 5, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, true, ".blockstm.ReadDescriptor"),
+				},
+				OneofDecl: oneofs("_start", "_end", "_stop"),
+			},
+			{
+				Name: proto.String("ReadSet"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("reads", 1, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, true, ".blockstm.ReadDescriptor"),
+					field("iterators", 2, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, true, ".blockstm.IteratorDescriptor"),
+				},
+			},
+		},
+	}
+	fd, err := protodesc.NewFile(fdp, nil)
+	require.NoError(t, err)
+	return fd
+}
+
+func TestProtoCodecGeneratedMessage(t *testing.T) {
+	schema := newProtoSchema(t)
+	readDesc := schema.Messages().ByName("ReadDescriptor")
+	iterDesc := schema.Messages().ByName("IteratorDescriptor")
+	rsDesc := schema.Messages().ByName("ReadSet")
+
+	newRead := func(key []byte, index int64, incarnation uint64) protoreflect.Value {
+		msg := dynamicpb.NewMessage(readDesc)
+		if key != nil {
+			msg.Set(readDesc.Fields().ByName("key"), protoreflect.ValueOfBytes(key))
+		}
+		msg.Set(readDesc.Fields().ByName("index"), protoreflect.ValueOfInt64(index))
+		msg.Set(readDesc.Fields().ByName("incarnation"), protoreflect.ValueOfUint64(incarnation))
+		return protoreflect.ValueOfMessage(msg)
+	}
+
+	// an iterator with the empty start, the unbounded end and the empty stop key
+	iter := dynamicpb.NewMessage(iterDesc)
+	iter.Set(iterDesc.Fields().ByName("start"), protoreflect.ValueOfBytes([]byte{}))
+	iter.Set(iterDesc.Fields().ByName("stop"), protoreflect.ValueOfBytes([]byte{}))
+	iter.Set(iterDesc.Fields().ByName("ascending"), protoreflect.ValueOfBool(true))
+	iterReads := iter.Mutable(iterDesc.Fields().ByName("reads")).List()
+	iterReads.Append(newRead([]byte{}, 1, 0))
+
+	rs := dynamicpb.NewMessage(rsDesc)
+	reads := rs.Mutable(rsDesc.Fields().ByName("reads")).List()
+	reads.Append(newRead([]byte("a"), -1, 0))
+	reads.Append(newRead([]byte{}, 2, 3))
+	rs.Mutable(rsDesc.Fields().ByName("iterators")).List().Append(protoreflect.ValueOfMessage(iter))
+
+	bz, err := proto.MarshalOptions{Deterministic: true}.Marshal(rs)
+	require.NoError(t, err)
+
+	expected := &ReadSet{
+		Reads: []ReadDescriptor{
+			{Key("a"), InvalidTxnVersion},
+			{Key{}, TxnVersion{2, 3}},
+		},
+		Iterators: []IteratorDescriptor{
+			{
+				IteratorOptions: IteratorOptions{Start: Key{}, End: nil, Ascending: true},
+				Stop:            Key{},
+				Reads:           []ReadDescriptor{{Key{}, TxnVersion{1, 0}}},
+			},
+		},
+	}
+	decoded, err := ProtoCodec.DecodeReadSet(bz)
+	require.NoError(t, err)
+	require.Equal(t, expected, decoded)
+
+	// and the other way around
+	msg := dynamicpb.NewMessage(rsDesc)
+	require.NoError(t, proto.Unmarshal(ProtoCodec.EncodeReadSet(expected), msg))
+	require.True(t, proto.Equal(rs, msg))
+}
@@ -0,0 +1,6 @@
+//go:build stmdebug
+
+package block_stm
+
+// DebugBuild is true when built with the `stmdebug` tag, which enables the defensive defaults.
+const DebugBuild = true
@@ -0,0 +1,195 @@
+package block_stm
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+
+	storetypes "cosmossdk.io/store/types"
+)
+
+// MaxDivergenceKeys limits the number of keys reported in a `Divergence`.
+const MaxDivergenceKeys = 10
+
+// DeterminismBlock is a block which can be executed repeatedly from the same pre-state.
+type DeterminismBlock interface {
+	Size() int
+	Stores() map[storetypes.StoreKey]int
+	// NewStorage returns a fresh copy of the pre-state for each run.
+	NewStorage() MultiStore
+	ExecuteTx(TxnIndex, MultiStore)
+	// TxResult returns the deterministic encoding of the result of the transaction in the last run.
+	TxResult(TxnIndex) []byte
+}
+
+// Divergence describes the first difference between a run and the reference run, i.e. the first one.
+type Divergence struct {
+	Run        int
+	Executors  int
+	GOMAXPROCS int
+
+	// Store is the name of the first diverged store, empty if the states are the same.
+	Store string
+	// Keys are the first keys with different values in the store.
+	Keys []Key
+	// Txn is the first transaction with a different result, -1 if the results are the same.
+	Txn TxnIndex
+}
+
+func (d *Divergence) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "run %d (executors: %d, GOMAXPROCS: %d) diverged", d.Run, d.Executors, d.GOMAXPROCS)
+	if d.Store != "" {
+		keys := make([]string, len(d.Keys))
+		for i, key := range d.Keys {
+			keys[i] = fmt.Sprintf("%x", key)
+		}
+		fmt.Fprintf(&sb, ", store %s keys: [%s]", d.Store, strings.Join(keys, ", "))
+	}
+	if d.Txn >= 0 {
+		fmt.Fprintf(&sb, ", tx %d result", d.Txn)
+	}
+	return sb.String()
+}
+
+// CheckDeterminism executes the block `runs` times, cycling through the executor counts and GOMAXPROCS settings,
+// and compares the final state of each store and the per-tx results with the first run, returns a `*Divergence`
+// on the first difference, the values are compared by `ValueType.Marshal`. The object stores are not compared, since
+// they are not part of the consensus state.
+func CheckDeterminism(block DeterminismBlock, runs int, executors ...int) error {
+	if len(executors) == 0 {
+		executors = []int{1, maxParallelism()}
+	}
+	procs := []int{runtime.NumCPU()}
+	if procs[0] > 1 {
+		procs = append(procs, 1, max(procs[0]/2, 2))
+	}
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+
+	stores := sortedConsensusStores(block.Stores())
+	var (
+		refStorage MultiStore
+		refDigests [][]byte
+		refResults [][]byte
+	)
+	for run := 0; run < runs; run++ {
+		n := executors[run%len(executors)]
+		p := procs[(run/len(executors))%len(procs)]
+		runtime.GOMAXPROCS(p)
+
+		storage := block.NewStorage()
+		if err := ExecuteBlock(context.Background(), block.Size(), block.Stores(), storage, n, block.ExecuteTx); err != nil {
+			return err
+		}
+
+		digests := make([][]byte, len(stores))
+		for i, key := range stores {
+			digests[i] = lookupStoreType(key).storeDigest(storage.GetStore(key))
+		}
+		results := make([][]byte, block.Size())
+		for txn := range results {
+			h := sha256.Sum256(block.TxResult(TxnIndex(txn)))
+			results[txn] = h[:]
+		}
+
+		if run == 0 {
+			refStorage, refDigests, refResults = storage, digests, results
+			continue
+		}
+
+		d := &Divergence{Run: run, Executors: n, GOMAXPROCS: p, Txn: -1}
+		for i, key := range stores {
+			if !bytes.Equal(refDigests[i], digests[i]) {
+				d.Store = key.Name()
+				d.Keys = lookupStoreType(key).divergedKeys(refStorage.GetStore(key), storage.GetStore(key))
+				break
+			}
+		}
+		for txn := range results {
+			if !bytes.Equal(refResults[txn], results[txn]) {
+				d.Txn = TxnIndex(txn)
+				break
+			}
+		}
+		if d.Store != "" || d.Txn >= 0 {
+			return d
+		}
+	}
+	return nil
+}
+
+// sortedConsensusStores returns the store keys except the object stores sorted by name, for deterministic reporting.
+func sortedConsensusStores(stores map[storetypes.StoreKey]int) []storetypes.StoreKey {
+	keys := make([]storetypes.StoreKey, 0, len(stores))
+	for key := range stores {
+		if isObjectStore(key) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Name() < keys[j].Name()
+	})
+	return keys
+}
+
+// kvStoreDigest hashes the length prefixed keys and encoded values in order.
+func kvStoreDigest[V any](store storetypes.GKVStore[V], marshal func(V) []byte) []byte {
+	h := sha256.New()
+	it := store.Iterator(nil, nil)
+	defer it.Close()
+
+	var buf []byte
+	for ; it.Valid(); it.Next() {
+		value := marshal(it.Value())
+		buf = binary.AppendUvarint(buf[:0], uint64(len(it.Key())))
+		buf = append(buf, it.Key()...)
+		buf = binary.AppendUvarint(buf, uint64(len(value)))
+		buf = append(buf, value...)
+		h.Write(buf)
+	}
+	return h.Sum(nil)
+}
+
+// divergedKeys merges the two stores in order, returns the first keys which are missing in one of them,
+// or have different encoded values.
+func divergedKeys[V any](a, b storetypes.GKVStore[V], marshal func(V) []byte) []Key {
+	iter1 := a.Iterator(nil, nil)
+	iter2 := b.Iterator(nil, nil)
+	defer iter1.Close()
+	defer iter2.Close()
+
+	var keys []Key
+	for len(keys) < MaxDivergenceKeys && (iter1.Valid() || iter2.Valid()) {
+		var cmp int
+		switch {
+		case !iter1.Valid():
+			cmp = 1
+		case !iter2.Valid():
+			cmp = -1
+		default:
+			cmp = bytes.Compare(iter1.Key(), iter2.Key())
+		}
+
+		switch {
+		case cmp < 0:
+			keys = append(keys, iter1.Key())
+			iter1.Next()
+		case cmp > 0:
+			keys = append(keys, iter2.Key())
+			iter2.Next()
+		default:
+			if !bytes.Equal(marshal(iter1.Value()), marshal(iter2.Value())) {
+				keys = append(keys, iter1.Key())
+			}
+			iter1.Next()
+			iter2.Next()
+		}
+	}
+	return keys
+}
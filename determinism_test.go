@@ -0,0 +1,83 @@
+package block_stm
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	storetypes "cosmossdk.io/store/types"
+	"github.com/test-go/testify/require"
+)
+
+// mockDeterminismBlock adapts `MockBlock` to `DeterminismBlock`, starting from empty stores.
+type mockDeterminismBlock struct {
+	*MockBlock
+	stores map[storetypes.StoreKey]int
+}
+
+func (b mockDeterminismBlock) Stores() map[storetypes.StoreKey]int {
+	return b.stores
+}
+
+func (b mockDeterminismBlock) NewStorage() MultiStore {
+	return NewMultiMemDB(b.stores)
+}
+
+func (b mockDeterminismBlock) TxResult(txn TxnIndex) []byte {
+	if err := b.Results[txn]; err != nil {
+		return []byte(err.Error())
+	}
+	return nil
+}
+
+func TestCheckDeterminism(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+
+	blk := mockDeterminismBlock{testBlock(100, 20), stores}
+	require.NoError(t, CheckDeterminism(blk, 6, 1, 4, 8))
+
+	// the value of "nondeterministic" key depends on the number of executions of the previous runs.
+	var counter atomic.Uint64
+	txs := []Tx{
+		NoopTx(0, "account0"),
+		func(store MultiStore) error {
+			store.GetKVStore(StoreKeyBank).Set(Key("nondeterministic"), []byte(fmt.Sprint(counter.Add(1))))
+			return nil
+		},
+		NoopTx(2, "account1"),
+	}
+	err := CheckDeterminism(mockDeterminismBlock{NewMockBlock(txs), stores}, 4, 2)
+	require.Equal(t, &Divergence{
+		Run:        1,
+		Executors:  2,
+		GOMAXPROCS: err.(*Divergence).GOMAXPROCS,
+		Store:      "bank",
+		Keys:       []Key{Key("nondeterministic")},
+		Txn:        -1,
+	}, err)
+
+	// the state is the same, but the result is not
+	txs[1] = func(MultiStore) error {
+		return fmt.Errorf("%d", counter.Add(1))
+	}
+	err = CheckDeterminism(mockDeterminismBlock{NewMockBlock(txs), stores}, 4, 2)
+	require.Equal(t, "", err.(*Divergence).Store)
+	require.Equal(t, TxnIndex(1), err.(*Divergence).Txn)
+	require.Contains(t, err.Error(), "tx 1 result")
+}
+
+func TestCheckDeterminismTypedStore(t *testing.T) {
+	key := &slotStoreKey{"slots"}
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, key: 1}
+
+	var counter atomic.Uint64
+	txs := []Tx{
+		func(store MultiStore) error {
+			store.GetStore(key).(storetypes.GKVStore[*slot]).Set(Key("nondeterministic"), &slot{byte(counter.Add(1))})
+			return nil
+		},
+	}
+	err := CheckDeterminism(mockDeterminismBlock{NewMockBlock(txs), stores}, 2, 1)
+	require.Equal(t, "slots", err.(*Divergence).Store)
+	require.Equal(t, []Key{Key("nondeterministic")}, err.(*Divergence).Keys)
+}
@@ -0,0 +1,49 @@
+package block_stm
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+)
+
+// record tags of the digest encoding
+const (
+	digestTagStore byte = iota
+	digestTagSet
+	digestTagDelete
+)
+
+// Digest returns the SHA-256 digest of the final snapshot, which is going to be written by `WriteSnapshot`,
+// it's deterministic for the same execution outcome regardless of the number of executors.
+//
+// The kv stores are encoded in the order of store name, each one as a store record followed by the set or delete
+// records of the written keys in order, the records are tagged, and the byte slices are prefixed with uvarint length.
+// The values are encoded with `ValueType.Marshal`, the object stores are not included.
+func (mv *MVMemory) Digest() []byte {
+	h := sha256.New()
+	for _, name := range mv.sortedStores() {
+		if isObjectStore(name) {
+			continue
+		}
+
+		writeDigestRecord(h, digestTagStore, []byte(name.Name()))
+		mv.data[mv.stores[name]].SnapshotMarshaled(func(key Key, value []byte) bool {
+			if value == nil {
+				writeDigestRecord(h, digestTagDelete, key)
+			} else {
+				writeDigestRecord(h, digestTagSet, key, value)
+			}
+			return true
+		})
+	}
+	return h.Sum(nil)
+}
+
+func writeDigestRecord(h hash.Hash, tag byte, fields ...[]byte) {
+	buf := []byte{tag}
+	for _, field := range fields {
+		buf = binary.AppendUvarint(buf, uint64(len(field)))
+		buf = append(buf, field...)
+	}
+	h.Write(buf)
+}
@@ -0,0 +1,56 @@
+package block_stm
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+
+	storetypes "cosmossdk.io/store/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestMVMemoryDigest(t *testing.T) {
+	objKey := storetypes.NewObjectStoreKey("obj")
+	stores := map[storetypes.StoreKey]int{StoreKeyBank: 0, StoreKeyAuth: 1, objKey: 2}
+	storage := NewMultiMemDB(stores)
+	mv := NewMVMemory(16, stores, storage, nil)
+
+	mview := mv.View(0)
+	mview.GetKVStore(StoreKeyBank).Set(Key("b"), []byte("1"))
+	mview.GetKVStore(StoreKeyBank).Set(Key("a"), []byte("1"))
+	mview.GetObjKVStore(objKey).Set(Key("a"), 1)
+	require.True(t, mv.Record(TxnVersion{0, 0}, mview))
+
+	mview = mv.View(1)
+	mview.GetKVStore(StoreKeyBank).Delete(Key("b"))
+	mview.GetKVStore(StoreKeyAuth).Set(Key("c"), []byte("2"))
+	require.True(t, mv.Record(TxnVersion{1, 0}, mview))
+
+	expected := sha256.Sum256([]byte(
+		"\x00\x03acc" + "\x01\x01c\x012" +
+			"\x00\x04bank" + "\x01\x01a\x011" + "\x02\x01b",
+	))
+	require.Equal(t, expected[:], mv.Digest())
+}
+
+func TestExecuteBlockDigest(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	blk := testBlock(100, 20)
+
+	var digests [][]byte
+	for _, executors := range []int{1, 4, 16} {
+		require.NoError(t, ExecuteBlock(
+			context.Background(), blk.Size(), stores, NewMultiMemDB(stores), executors, blk.ExecuteTx,
+			WithDigest(func(digest []byte) { digests = append(digests, digest) }),
+		))
+	}
+	require.Equal(t, digests[0], digests[1])
+	require.Equal(t, digests[0], digests[2])
+
+	blk = testBlock(100, 10)
+	require.NoError(t, ExecuteBlock(
+		context.Background(), blk.Size(), stores, NewMultiMemDB(stores), 4, blk.ExecuteTx,
+		WithDigest(func(digest []byte) { digests = append(digests, digest) }),
+	))
+	require.NotEqual(t, digests[0], digests[3])
+}
@@ -69,9 +69,10 @@ func (e *Executor) TryExecute(version TxnVersion) (TxnVersion, TaskKind) {
 
 func (e *Executor) NeedsReexecution(version TxnVersion) (TxnVersion, TaskKind) {
 	e.scheduler.validatedTxns.Add(1)
-	valid := e.mvMemory.ValidateReadSet(version.Index)
-	aborted := !valid && e.scheduler.TryValidationAbort(version)
+	conflict := e.mvMemory.FindConflict(version.Index)
+	aborted := conflict != nil && e.scheduler.TryValidationAbort(version)
 	if aborted {
+		e.mvMemory.RecordAbort(version, conflict)
 		e.mvMemory.ConvertWritesToEstimates(version.Index)
 	}
 	return e.scheduler.FinishValidation(version.Index, aborted)
@@ -79,6 +80,6 @@ func (e *Executor) NeedsReexecution(version TxnVersion) (TxnVersion, TaskKind) {
 
 func (e *Executor) execute(txn TxnIndex) *MultiMVMemoryView {
 	view := e.mvMemory.View(txn)
-	e.txExecutor(txn, view)
+	e.mvMemory.ExecuteTx(e.txExecutor, txn, view)
 	return view
 }
@@ -0,0 +1,165 @@
+package block_stm
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"io"
+	"math"
+	"reflect"
+)
+
+// BytesFingerprint is the `ValueType.Fingerprint` of the `[]byte` values.
+func BytesFingerprint(v []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(v)
+	return h.Sum64()
+}
+
+// DeepFingerprint hashes everything reachable from the value, following the pointers, slices, maps and interfaces, so
+// the mutations behind the pointers are detected, unlike the formatted representation which prints the addresses.
+// The maps are hashed regardless of the iteration order, the cycles are cut at the revisited references, the funcs,
+// channels and unsafe pointers are hashed by identity.
+func DeepFingerprint(v any) uint64 {
+	f := fingerprinter{h: fnv.New64a(), visiting: make(map[visit]struct{})}
+	f.value(reflect.ValueOf(v))
+	return f.h.Sum64()
+}
+
+// visit identifies a reference being hashed, the type is included because a struct and its first field have the same
+// address.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+type fingerprinter struct {
+	h   hash.Hash64
+	buf [8]byte
+	// the references on the current path, to cut the cycles
+	visiting map[visit]struct{}
+}
+
+func (f *fingerprinter) uint(x uint64) {
+	binary.LittleEndian.PutUint64(f.buf[:], x)
+	f.h.Write(f.buf[:])
+}
+
+// enter returns false if the reference is already on the path, otherwise the caller must call `leave` after hashing it.
+func (f *fingerprinter) enter(v reflect.Value) (visit, bool) {
+	key := visit{v.Pointer(), v.Type(), 0}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+	if _, ok := f.visiting[key]; ok {
+		return key, false
+	}
+	f.visiting[key] = struct{}{}
+	return key, true
+}
+
+func (f *fingerprinter) leave(key visit) {
+	delete(f.visiting, key)
+}
+
+func (f *fingerprinter) value(v reflect.Value) {
+	if !v.IsValid() {
+		f.uint(0)
+		return
+	}
+	f.uint(uint64(v.Kind()))
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			f.uint(1)
+		} else {
+			f.uint(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f.uint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		f.uint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		f.uint(math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		f.uint(math.Float64bits(real(c)))
+		f.uint(math.Float64bits(imag(c)))
+	case reflect.String:
+		f.uint(uint64(v.Len()))
+		io.WriteString(f.h, v.String())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			f.value(v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			f.value(v.Field(i))
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			f.uint(0)
+			return
+		}
+		f.uint(uint64(v.Len()) + 1)
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			f.h.Write(v.Bytes())
+			return
+		}
+		key, ok := f.enter(v)
+		if !ok {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			f.value(v.Index(i))
+		}
+		f.leave(key)
+	case reflect.Pointer:
+		if v.IsNil() {
+			f.uint(0)
+			return
+		}
+		f.uint(1)
+		key, ok := f.enter(v)
+		if !ok {
+			return
+		}
+		f.value(v.Elem())
+		f.leave(key)
+	case reflect.Interface:
+		if v.IsNil() {
+			f.uint(0)
+			return
+		}
+		io.WriteString(f.h, v.Elem().Type().String())
+		f.value(v.Elem())
+	case reflect.Map:
+		if v.IsNil() {
+			f.uint(0)
+			return
+		}
+		f.uint(uint64(v.Len()) + 1)
+		key, ok := f.enter(v)
+		if !ok {
+			return
+		}
+		// sum the hashes of the entries, so the iteration order doesn't matter
+		var sum uint64
+		h := f.h
+		iter := v.MapRange()
+		for iter.Next() {
+			f.h = fnv.New64a()
+			f.value(iter.Key())
+			f.value(iter.Value())
+			sum += f.h.Sum64()
+		}
+		f.h = h
+		f.uint(sum)
+		f.leave(key)
+	default:
+		// func, chan and unsafe pointer
+		f.uint(uint64(v.Pointer()))
+	}
+}
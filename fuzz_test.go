@@ -0,0 +1,175 @@
+package block_stm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	storetypes "cosmossdk.io/store/types"
+	"github.com/test-go/testify/require"
+)
+
+const (
+	fuzzOpGet = iota
+	fuzzOpSet
+	fuzzOpDelete
+	fuzzOpIterator
+	fuzzOpReverseIterator
+	fuzzOpEndTx
+	fuzzOpCount
+)
+
+const (
+	fuzzMaxTxs = 64
+	fuzzKeys   = 16
+)
+
+var fuzzStoreKeys = []storetypes.StoreKey{
+	storetypes.NewKVStoreKey("fuzz0"),
+	storetypes.NewKVStoreKey("fuzz1"),
+	storetypes.NewObjectStoreKey("fuzzobj0"),
+	storetypes.NewObjectStoreKey("fuzzobj1"),
+}
+
+type fuzzOp struct {
+	kind  byte
+	store int
+	key   byte
+	arg   byte
+	limit byte
+}
+
+// parseFuzzProgram decodes 4-byte ops: `kind+store, key, value or end key, iterator limit`,
+// small key space is used to produce conflicts.
+func parseFuzzProgram(program []byte) [][]fuzzOp {
+	txs := [][]fuzzOp{nil}
+	for ; len(program) >= 4 && len(txs) <= fuzzMaxTxs; program = program[4:] {
+		op := fuzzOp{
+			kind:  program[0] % fuzzOpCount,
+			store: int(program[0]/fuzzOpCount) % len(fuzzStoreKeys),
+			key:   program[1] % fuzzKeys,
+			arg:   program[2],
+			limit: program[3] % 8,
+		}
+		if op.kind == fuzzOpEndTx {
+			txs = append(txs, nil)
+			continue
+		}
+		last := len(txs) - 1
+		txs[last] = append(txs[last], op)
+	}
+	return txs
+}
+
+func fuzzKey(k byte) []byte {
+	return []byte{'k', k}
+}
+
+// fuzzBlock builds the block, each transaction records the values it observed into `observed`.
+func fuzzBlock(txs [][]fuzzOp, observed [][]string) *MockBlock {
+	block := make([]Tx, len(txs))
+	for i, ops := range txs {
+		i, ops := i, ops
+		block[i] = func(store MultiStore) error {
+			var log []string
+			for _, op := range ops {
+				key := fuzzStoreKeys[op.store]
+				if _, ok := key.(*storetypes.ObjectStoreKey); ok {
+					log = runFuzzOp(log, store.GetObjKVStore(key), op, func() any { return int(op.arg) + i<<8 })
+				} else {
+					log = runFuzzOp(log, store.GetKVStore(key), op, func() []byte { return []byte{op.arg, byte(i)} })
+				}
+			}
+			observed[i] = log
+			return nil
+		}
+	}
+	return NewMockBlock(block)
+}
+
+func runFuzzOp[V any](log []string, store storetypes.GKVStore[V], op fuzzOp, value func() V) []string {
+	key := fuzzKey(op.key)
+	switch op.kind {
+	case fuzzOpGet:
+		log = append(log, fmt.Sprintf("get %x=%v", key, store.Get(key)))
+	case fuzzOpSet:
+		store.Set(key, value())
+	case fuzzOpDelete:
+		store.Delete(key)
+	case fuzzOpIterator, fuzzOpReverseIterator:
+		start, end := key, fuzzKey(op.arg%fuzzKeys)
+		if string(start) > string(end) {
+			start, end = end, start
+		}
+		if string(start) == string(end) {
+			// unbounded on one side
+			end = nil
+		}
+
+		var it storetypes.GIterator[V]
+		if op.kind == fuzzOpIterator {
+			it = store.Iterator(start, end)
+		} else {
+			it = store.ReverseIterator(start, end)
+		}
+		var items []string
+		for i := byte(0); i < op.limit && it.Valid(); i++ {
+			items = append(items, fmt.Sprintf("%x=%v", it.Key(), it.Value()))
+			it.Next()
+		}
+		it.Close()
+		log = append(log, fmt.Sprintf("iter(%d) %x-%x: %s", op.kind, start, end, strings.Join(items, ",")))
+	}
+	return log
+}
+
+func FuzzSTM(f *testing.F) {
+	f.Add(uint8(4), []byte{})
+	f.Add(uint8(2), []byte{
+		fuzzOpSet, 1, 1, 0, fuzzOpEndTx, 0, 0, 0,
+		fuzzOpGet, 1, 0, 0, fuzzOpSet, 1, 2, 0, fuzzOpEndTx, 0, 0, 0,
+		fuzzOpDelete, 1, 0, 0, fuzzOpIterator, 0, 15, 7,
+	})
+	f.Add(uint8(8), []byte{
+		fuzzOpSet + fuzzOpCount*2, 3, 1, 0, fuzzOpSet + fuzzOpCount*3, 4, 2, 0, fuzzOpEndTx, 0, 0, 0,
+		fuzzOpReverseIterator + fuzzOpCount*2, 0, 15, 7, fuzzOpGet + fuzzOpCount*3, 4, 0, 0, fuzzOpEndTx, 0, 0, 0,
+		fuzzOpDelete + fuzzOpCount*2, 3, 0, 0, fuzzOpSet + fuzzOpCount, 3, 9, 0, fuzzOpEndTx, 0, 0, 0,
+		fuzzOpIterator + fuzzOpCount, 3, 3, 5, fuzzOpReverseIterator, 0, 0, 7,
+	})
+	// found by the fuzzer, the iterators stopped early used to fail the validation forever.
+	f.Add(uint8(3), []byte{
+		0x2, 0x4, 0x0, 0x16, 0x3, 0x4, 0x3, 0x7b, 0x2, 0x5, 0x0, 0xce, 0x5, 0x0, 0x2, 0x17,
+		0x0, 0x0, 0x4, 0xe4, 0x1, 0x4, 0x4, 0x49, 0x1, 0x3, 0x5, 0x19, 0x4, 0x3, 0x1, 0xd6,
+		0x3, 0x2, 0x2, 0xd0, 0x5, 0x0, 0x4, 0x61, 0x4, 0x4, 0x3, 0x4,
+	})
+
+	stores := make(map[storetypes.StoreKey]int, len(fuzzStoreKeys))
+	for i, key := range fuzzStoreKeys {
+		stores[key] = i
+	}
+
+	f.Fuzz(func(t *testing.T, executors uint8, program []byte) {
+		txs := parseFuzzProgram(program)
+
+		expObserved := make([][]string, len(txs))
+		expStorage := NewMultiMemDB(stores)
+		runSequential(expStorage, fuzzBlock(txs, expObserved))
+
+		observed := make([][]string, len(txs))
+		storage := NewMultiMemDB(stores)
+		blk := fuzzBlock(txs, observed)
+		require.NoError(t, ExecuteBlock(
+			context.Background(), blk.Size(), stores, storage, 1+int(executors%16), blk.ExecuteTx,
+		))
+
+		require.Equal(t, expObserved, observed)
+		for _, key := range fuzzStoreKeys {
+			if _, ok := key.(*storetypes.ObjectStoreKey); ok {
+				require.True(t, objStoreEqual(expStorage.GetObjKVStore(key), storage.GetObjKVStore(key)), key.Name())
+			} else {
+				require.True(t, StoreEqual(expStorage.GetKVStore(key), storage.GetKVStore(key)), key.Name())
+			}
+		}
+	})
+}
@@ -7,6 +7,7 @@ require (
 	github.com/cometbft/cometbft v0.38.6
 	github.com/test-go/testify v1.1.4
 	github.com/tidwall/btree v1.7.0
+	google.golang.org/protobuf v1.33.0
 )
 
 require (
@@ -64,7 +65,6 @@ require (
 	golang.org/x/text v0.14.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20231212172506-995d672761c0 // indirect
 	google.golang.org/grpc v1.60.1 // indirect
-	google.golang.org/protobuf v1.33.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
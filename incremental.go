@@ -0,0 +1,154 @@
+package block_stm
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExecuteBlockIncremental executes a modified block by reusing the previous execution of the original block,
+// which must be executed on the same parent state with the same stores, e.g. `PendingBlock.MVMemory()` of a dry-run.
+//
+// `origins` maps each transaction of the new block to its index in the previous block, `-1` for new or changed
+// transactions, it's length is the new block size. The write sets of the unchanged transactions are reused and only
+// validated, they are re-executed only if their read sets are invalidated by the change, so the caller should keep the
+// results of the previous execution for the transactions not re-executed.
+func ExecuteBlockIncremental(
+	ctx context.Context,
+	prev *MVMemory,
+	origins []TxnIndex,
+	storage MultiStore,
+	executors int,
+	txExecutor TxExecutor,
+	opts ...Option,
+) error {
+	if executors < 0 {
+		return fmt.Errorf("invalid number of executors: %d", executors)
+	}
+
+	if err := ValidateStores(prev.stores, storage); err != nil {
+		return err
+	}
+
+	o := newOptions(opts)
+	if err := o.validate(prev.stores); err != nil {
+		return err
+	}
+
+	scheduler := NewScheduler(len(origins))
+	mvMemory := NewMVMemoryFromPrevious(prev, origins, storage, scheduler)
+	mvMemory.SetViewOptions(o.viewOptions)
+	return runBlock(ctx, scheduler, mvMemory, storage, executors, txExecutor, o)
+}
+
+// NewMVMemoryFromPrevious creates a `MVMemory` for the new block, initialized with the reused write sets and read sets
+// of the previous execution, the reused transactions are marked as executed in the scheduler.
+func NewMVMemoryFromPrevious(
+	prev *MVMemory, origins []TxnIndex,
+	storage MultiStore, scheduler *Scheduler,
+) *MVMemory {
+	mv := NewMVMemory(len(origins), prev.stores, storage, scheduler)
+
+	// previous txn index -> new txn index, only for the reused transactions
+	remapped := make(map[TxnIndex]TxnIndex, len(origins))
+	for txn, origin := range origins {
+		if origin < 0 || origin >= prev.BlockSize() {
+			continue
+		}
+
+		rs := prev.lastReadSet[origin].Load()
+		if rs == nil {
+			continue
+		}
+
+		// the read set is only reusable if all the observed writers are reused
+		readSet, ok := remapMultiReadSet(*rs, remapped)
+		if !ok {
+			continue
+		}
+
+		version := TxnVersion{Index: TxnIndex(txn)}
+		prevLocations := prev.readLastWrittenLocations(origin)
+		newLocations := make(MultiLocations, len(prevLocations))
+		for store, locations := range prevLocations {
+			newLocations[store] = mv.data[store].CopyTxnWrites(prev.data[store], origin, version, locations)
+		}
+
+		mv.lastWrittenLocations[txn].Store(&newLocations)
+		mv.lastReadSet[txn].Store(&readSet)
+		remapped[origin] = TxnIndex(txn)
+		scheduler.SetExecuted(TxnIndex(txn))
+	}
+
+	return mv
+}
+
+// DiffTxs computes the `origins` argument of `ExecuteBlockIncremental`, by matching the identifiers (e.g. hashes) of
+// the transactions in the new block to the previous one, the duplicated ones are matched in order.
+func DiffTxs(prev, txs [][]byte) []TxnIndex {
+	indexes := make(map[string][]TxnIndex, len(prev))
+	for i, tx := range prev {
+		indexes[string(tx)] = append(indexes[string(tx)], TxnIndex(i))
+	}
+
+	origins := make([]TxnIndex, len(txs))
+	for i, tx := range txs {
+		candidates := indexes[string(tx)]
+		if len(candidates) == 0 {
+			origins[i] = -1
+			continue
+		}
+		origins[i] = candidates[0]
+		indexes[string(tx)] = candidates[1:]
+	}
+	return origins
+}
+
+// remapMultiReadSet rewrites the versions in the read sets to the new txn indexes, returns false if any observed writer
+// is not reused.
+func remapMultiReadSet(rs MultiReadSet, remapped map[TxnIndex]TxnIndex) (MultiReadSet, bool) {
+	result := make(MultiReadSet, len(rs))
+	for store, readSet := range rs {
+		newReadSet, ok := remapReadSet(readSet, remapped)
+		if !ok {
+			return nil, false
+		}
+		result[store] = newReadSet
+	}
+	return result, true
+}
+
+func remapReadSet(rs *ReadSet, remapped map[TxnIndex]TxnIndex) (*ReadSet, bool) {
+	reads, ok := remapReads(rs.Reads, remapped)
+	if !ok {
+		return nil, false
+	}
+
+	iterators := make([]IteratorDescriptor, len(rs.Iterators))
+	for i, desc := range rs.Iterators {
+		iterators[i] = desc
+		if iterators[i].Reads, ok = remapReads(desc.Reads, remapped); !ok {
+			return nil, false
+		}
+	}
+
+	return &ReadSet{Reads: reads, Iterators: iterators}, true
+}
+
+func remapReads(reads []ReadDescriptor, remapped map[TxnIndex]TxnIndex) ([]ReadDescriptor, bool) {
+	result := make([]ReadDescriptor, len(reads))
+	for i, desc := range reads {
+		result[i] = desc
+		if !desc.Version.Valid() {
+			// read from storage
+			continue
+		}
+
+		txn, ok := remapped[desc.Version.Index]
+		if !ok {
+			return nil, false
+		}
+		// reused write sets are all written as incarnation 0
+		result[i].Version = TxnVersion{Index: txn}
+	}
+	return result, true
+}
@@ -0,0 +1,89 @@
+package block_stm
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	storetypes "cosmossdk.io/store/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestDiffTxs(t *testing.T) {
+	prev := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("b")}
+	txs := [][]byte{[]byte("a"), []byte("b"), []byte("d"), []byte("b"), []byte("b")}
+	require.Equal(t, []TxnIndex{0, 1, -1, 3, -1}, DiffTxs(prev, txs))
+}
+
+func TestExecuteBlockIncremental(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	storage := NewMultiMemDB(stores)
+	prevBlk := NewMockBlock([]Tx{
+		BankTransferTx(0, "a", "b", 10),
+		BankTransferTx(1, "c", "d", 10),
+		BankTransferTx(2, "b", "e", 5), // reads the write of txn 0
+		BankTransferTx(3, "f", "g", 10),
+		BankTransferTx(4, "e", "h", 1), // reads the write of txn 2
+		BankTransferTx(5, "d", "i", 1), // reads the write of txn 1
+	})
+
+	var pending *PendingBlock
+	require.NoError(t, ExecuteBlock(
+		context.Background(), prevBlk.Size(), stores, storage, 10, prevBlk.ExecuteTx,
+		WithDryRun(func(p *PendingBlock) {
+			pending = p
+		}),
+	))
+
+	// drop txn 1, insert a new txn writing "e" before txn 2
+	txs := []Tx{
+		prevBlk.Txs[0],
+		BankTransferTx(6, "a", "e", 1),
+		prevBlk.Txs[2],
+		prevBlk.Txs[3],
+		prevBlk.Txs[4],
+		prevBlk.Txs[5],
+	}
+	origins := []TxnIndex{0, -1, 2, 3, 4, 5}
+	blk := NewMockBlock(txs)
+
+	var (
+		mtx      sync.Mutex
+		executed = make(map[TxnIndex]struct{})
+	)
+	require.NoError(t, ExecuteBlockIncremental(
+		context.Background(), pending.MVMemory(), origins, storage, 10,
+		func(txn TxnIndex, store MultiStore) {
+			mtx.Lock()
+			executed[txn] = struct{}{}
+			mtx.Unlock()
+			blk.ExecuteTx(txn, store)
+		},
+	))
+	// the new txn, the txns reading it directly or transitively, and the one reading the dropped txn
+	require.Equal(t, map[TxnIndex]struct{}{1: {}, 2: {}, 4: {}, 5: {}}, executed)
+
+	fullExecution := NewMultiMemDB(stores)
+	require.NoError(t, ExecuteBlock(context.Background(), blk.Size(), stores, fullExecution, 10, blk.ExecuteTx))
+	for store := range stores {
+		require.True(t, StoreEqual(fullExecution.GetKVStore(store), storage.GetKVStore(store)))
+	}
+}
+
+func TestExecuteBlockIncrementalUnknownStore(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	blk := NewMockBlock([]Tx{BankTransferTx(0, "a", "b", 10)})
+
+	var pending *PendingBlock
+	require.NoError(t, ExecuteBlock(
+		context.Background(), blk.Size(), stores, NewMultiMemDB(stores), 1, blk.ExecuteTx,
+		WithDryRun(func(p *PendingBlock) {
+			pending = p
+		}),
+	))
+
+	// the parent storage of the new block misses a store
+	storage := NewMultiMemDB(map[storetypes.StoreKey]int{StoreKeyAuth: 0})
+	err := ExecuteBlockIncremental(context.Background(), pending.MVMemory(), []TxnIndex{0}, storage, 1, blk.ExecuteTx)
+	require.Equal(t, ErrUnknownStore{Store: "bank", Parent: true}, err)
+}
@@ -0,0 +1,67 @@
+package block_stm
+
+import (
+	"sort"
+
+	storetypes "cosmossdk.io/store/types"
+)
+
+// ChangeListener receives the final writes of a transaction, it's called once for each transaction in order,
+// the change records are grouped by store, sorted by store name and key, `nil` if the transaction writes nothing.
+type ChangeListener func(TxnIndex, []*storetypes.StoreKVPair)
+
+// MemoryListenerAdapter feeds the change records into a `storetypes.MemoryListener`, in transaction order.
+func MemoryListenerAdapter(listener *storetypes.MemoryListener, stores map[storetypes.StoreKey]int) ChangeListener {
+	keys := make(map[string]storetypes.StoreKey, len(stores))
+	for key := range stores {
+		keys[key.Name()] = key
+	}
+	return func(_ TxnIndex, changes []*storetypes.StoreKVPair) {
+		for _, pair := range changes {
+			listener.OnWrite(keys[pair.StoreKey], pair.Key, pair.Value, pair.Delete)
+		}
+	}
+}
+
+// StreamChanges calls the listener with the change records of each transaction in order,
+// generated from the final multi-version data, should only be called after the block is executed.
+// The values are encoded with `ValueType.Marshal`, the object stores are skipped.
+func (mv *MVMemory) StreamChanges(listener ChangeListener) {
+	stores := mv.sortedStores()
+	for txn := range mv.lastWrittenLocations {
+		listener(TxnIndex(txn), mv.txnChanges(TxnIndex(txn), stores))
+	}
+}
+
+func (mv *MVMemory) txnChanges(txn TxnIndex, stores []storetypes.StoreKey) []*storetypes.StoreKVPair {
+	var changes []*storetypes.StoreKVPair
+	for _, cs := range mv.txnChangeSet(txn, stores).Stores {
+		if isObjectStore(cs.Store) {
+			continue
+		}
+
+		data := mv.data[mv.stores[cs.Store]]
+		for _, pair := range cs.Pairs {
+			value := data.MarshalValue(pair.Value)
+			changes = append(changes, &storetypes.StoreKVPair{
+				StoreKey: cs.Store.Name(),
+				Delete:   value == nil,
+				Key:      pair.Key,
+				Value:    value,
+			})
+		}
+	}
+	return changes
+}
+
+// sortedStores returns the store keys sorted by name, for deterministic ordering.
+func (mv *MVMemory) sortedStores() []storetypes.StoreKey {
+	stores := make([]storetypes.StoreKey, 0, len(mv.stores))
+	for key := range mv.stores {
+		stores = append(stores, key)
+	}
+	sort.Slice(stores, func(i, j int) bool {
+		return stores[i].Name() < stores[j].Name()
+	})
+	return stores
+}
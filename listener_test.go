@@ -0,0 +1,79 @@
+package block_stm
+
+import (
+	"context"
+	"testing"
+
+	storetypes "cosmossdk.io/store/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestStreamChanges(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	storage := NewMultiMemDB(stores)
+	mv := NewMVMemory(3, stores, storage, nil)
+
+	mview := mv.View(0)
+	mview.GetKVStore(StoreKeyBank).Set(Key("b"), []byte("1"))
+	mview.GetKVStore(StoreKeyAuth).Set(Key("a"), []byte("1"))
+	require.True(t, mv.Record(TxnVersion{0, 0}, mview))
+
+	mview = mv.View(2)
+	mview.GetKVStore(StoreKeyAuth).Delete(Key("a"))
+	require.True(t, mv.Record(TxnVersion{2, 0}, mview))
+
+	var txns []TxnIndex
+	var changes [][]*storetypes.StoreKVPair
+	mv.StreamChanges(func(txn TxnIndex, pairs []*storetypes.StoreKVPair) {
+		txns = append(txns, txn)
+		changes = append(changes, pairs)
+	})
+
+	require.Equal(t, []TxnIndex{0, 1, 2}, txns)
+	require.Equal(t, [][]*storetypes.StoreKVPair{
+		{
+			{StoreKey: "acc", Key: Key("a"), Value: []byte("1")},
+			{StoreKey: "bank", Key: Key("b"), Value: []byte("1")},
+		},
+		nil,
+		{
+			{StoreKey: "acc", Key: Key("a"), Delete: true},
+		},
+	}, changes)
+}
+
+func TestExecuteBlockWithChangeListener(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	blk := iterateBlock(100, 10)
+	storage := NewMultiMemDB(stores)
+	listener := storetypes.NewMemoryListener()
+
+	var txns []TxnIndex
+	adapter := MemoryListenerAdapter(listener, stores)
+	require.NoError(t, ExecuteBlock(
+		context.Background(), blk.Size(), stores, storage, 10, blk.ExecuteTx,
+		WithChangeListener(func(txn TxnIndex, pairs []*storetypes.StoreKVPair) {
+			txns = append(txns, txn)
+			require.NotEmpty(t, pairs)
+			adapter(txn, pairs)
+		}),
+	))
+	require.Len(t, txns, blk.Size())
+
+	// replaying the change records in order reproduces the final state
+	replay := NewMultiMemDB(stores)
+	for _, pair := range listener.PopStateCache() {
+		var key storetypes.StoreKey = StoreKeyAuth
+		if pair.StoreKey == StoreKeyBank.Name() {
+			key = StoreKeyBank
+		}
+		if pair.Delete {
+			replay.GetKVStore(key).Delete(pair.Key)
+		} else {
+			replay.GetKVStore(key).Set(pair.Key, pair.Value)
+		}
+	}
+	for store := range stores {
+		require.True(t, StoreEqual(storage.GetKVStore(store), replay.GetKVStore(store)))
+	}
+}
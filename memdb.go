@@ -58,6 +58,15 @@ func NewGMemDBNonConcurrent[V any](
 	}
 }
 
+// Copy returns a copy-on-write clone of the db, cheap to call.
+func (db *GMemDB[V]) Copy() *GMemDB[V] {
+	return &GMemDB[V]{
+		BTreeG:   *db.BTreeG.Copy(),
+		isZero:   db.isZero,
+		valueLen: db.valueLen,
+	}
+}
+
 func (db *GMemDB[V]) Scan(cb func(key Key, value V) bool) {
 	db.BTreeG.Scan(func(item memdbItem[V]) bool {
 		return cb(item.key, item.value)
@@ -181,12 +190,7 @@ var _ MultiStore = (*MultiMemDB)(nil)
 func NewMultiMemDB(stores map[storetypes.StoreKey]int) *MultiMemDB {
 	dbs := make(map[storetypes.StoreKey]storetypes.Store, len(stores))
 	for name := range stores {
-		switch name.(type) {
-		case *storetypes.ObjectStoreKey:
-			dbs[name] = NewObjMemDB()
-		default:
-			dbs[name] = NewMemDB()
-		}
+		dbs[name] = lookupStoreType(name).NewMemDB()
 	}
 	return &MultiMemDB{
 		dbs: dbs,
@@ -1,18 +1,38 @@
 package block_stm
 
-import storetypes "cosmossdk.io/store/types"
+import (
+	"fmt"
+	"io"
+
+	"cosmossdk.io/store/cachemulti"
+	"cosmossdk.io/store/tracekv"
+	storetypes "cosmossdk.io/store/types"
+)
 
 const ViewsPreAllocate = 4
 
+// storeNameCtxKey is the TraceContext metadata key that identifies
+// the store which emitted a given trace, same as the one used in cosmos-sdk.
+const storeNameCtxKey = "store_name"
+
 // MultiMVMemoryView don't need to be thread-safe, there's a dedicated instance for each tx execution.
 type MultiMVMemoryView struct {
 	stores    map[storetypes.StoreKey]int
 	views     map[storetypes.StoreKey]MVView
 	newMVView func(storetypes.StoreKey, TxnIndex) MVView
 	txn       TxnIndex
+
+	// write set checkpoints, store -> opaque write set snapshot
+	checkpoints []map[storetypes.StoreKey]any
+
+	traceWriter  io.Writer
+	traceContext storetypes.TraceContext
 }
 
-var _ MultiStore = (*MultiMVMemoryView)(nil)
+var (
+	_ MultiStore            = (*MultiMVMemoryView)(nil)
+	_ storetypes.MultiStore = (*MultiMVMemoryView)(nil)
+)
 
 func NewMultiMVMemoryView(
 	stores map[storetypes.StoreKey]int,
@@ -30,6 +50,9 @@ func NewMultiMVMemoryView(
 func (mv *MultiMVMemoryView) getViewOrInit(name storetypes.StoreKey) MVView {
 	view, ok := mv.views[name]
 	if !ok {
+		if _, ok := mv.stores[name]; !ok {
+			panic(ErrUnknownStore{Store: name.Name()})
+		}
 		view = mv.newMVView(name, mv.txn)
 		mv.views[name] = view
 	}
@@ -41,13 +64,150 @@ func (mv *MultiMVMemoryView) GetStore(name storetypes.StoreKey) storetypes.Store
 }
 
 func (mv *MultiMVMemoryView) GetKVStore(name storetypes.StoreKey) storetypes.KVStore {
-	return mv.GetStore(name).(storetypes.KVStore)
+	store := mv.GetStore(name).(storetypes.KVStore)
+	if mv.TracingEnabled() {
+		store = tracekv.NewStore(store, mv.traceWriter, mv.storeTraceContext(name))
+	}
+	return store
 }
 
 func (mv *MultiMVMemoryView) GetObjKVStore(name storetypes.StoreKey) storetypes.ObjKVStore {
 	return mv.GetStore(name).(storetypes.ObjKVStore)
 }
 
+// GetStoreType implements types.Store.
+func (mv *MultiMVMemoryView) GetStoreType() storetypes.StoreType {
+	return storetypes.StoreTypeMulti
+}
+
+// CacheWrap implements types.Store.
+func (mv *MultiMVMemoryView) CacheWrap() storetypes.CacheWrap {
+	return mv.CacheMultiStore().(storetypes.CacheWrap)
+}
+
+// CacheWrapWithTrace implements types.Store.
+func (mv *MultiMVMemoryView) CacheWrapWithTrace(w io.Writer, tc storetypes.TraceContext) storetypes.CacheWrap {
+	return cacheMultiStore{cachemulti.NewStore(mv.cacheWrappers(), w, tc), mv.stores}
+}
+
+// CacheMultiStore implements types.MultiStore, it branches all the stores of the view,
+// `Write()` on the returned store flushes the changes into the write sets of this view.
+func (mv *MultiMVMemoryView) CacheMultiStore() storetypes.CacheMultiStore {
+	return cacheMultiStore{cachemulti.NewStore(mv.cacheWrappers(), mv.traceWriter, mv.traceContext), mv.stores}
+}
+
+// cacheWrappers initializes the views of all the stores, because `cachemulti.Store` requires all the stores upfront.
+func (mv *MultiMVMemoryView) cacheWrappers() map[storetypes.StoreKey]storetypes.CacheWrapper {
+	stores := make(map[storetypes.StoreKey]storetypes.CacheWrapper, len(mv.stores))
+	for name := range mv.stores {
+		stores[name] = mv.getViewOrInit(name)
+	}
+	return stores
+}
+
+// cacheMultiStore is a branch of the view, the unknown stores panic with `ErrUnknownStore` like the view itself,
+// instead of the plain string panic of `cachemulti.Store`, so `ExecuteBlock` can report them.
+type cacheMultiStore struct {
+	cacheBranch
+	stores map[storetypes.StoreKey]int
+}
+
+// cacheBranch is the wrapped store, aliased to not clash with the `CacheMultiStore` method.
+type cacheBranch = storetypes.CacheMultiStore
+
+var _ storetypes.CacheWrap = cacheMultiStore{}
+
+func (cms cacheMultiStore) checkStore(name storetypes.StoreKey) {
+	if _, ok := cms.stores[name]; !ok {
+		panic(ErrUnknownStore{Store: name.Name()})
+	}
+}
+
+func (cms cacheMultiStore) GetStore(name storetypes.StoreKey) storetypes.Store {
+	cms.checkStore(name)
+	return cms.cacheBranch.GetStore(name)
+}
+
+func (cms cacheMultiStore) GetKVStore(name storetypes.StoreKey) storetypes.KVStore {
+	cms.checkStore(name)
+	return cms.cacheBranch.GetKVStore(name)
+}
+
+func (cms cacheMultiStore) GetObjKVStore(name storetypes.StoreKey) storetypes.ObjKVStore {
+	cms.checkStore(name)
+	return cms.cacheBranch.GetObjKVStore(name)
+}
+
+// CacheMultiStore implements types.MultiStore, the nested branches are wrapped too.
+func (cms cacheMultiStore) CacheMultiStore() storetypes.CacheMultiStore {
+	return cacheMultiStore{cms.cacheBranch.CacheMultiStore(), cms.stores}
+}
+
+// CacheWrap implements types.Store.
+func (cms cacheMultiStore) CacheWrap() storetypes.CacheWrap {
+	return cms.CacheMultiStore().(storetypes.CacheWrap)
+}
+
+// Discard implements types.CacheWrap.
+func (cms cacheMultiStore) Discard() {
+	cms.cacheBranch.(storetypes.CacheWrap).Discard()
+}
+
+// RunAtomic implements types.CacheMultiStore, the branch passed to the callback is wrapped too.
+func (cms cacheMultiStore) RunAtomic(cb func(storetypes.CacheMultiStore) error) error {
+	return cms.cacheBranch.RunAtomic(func(branch storetypes.CacheMultiStore) error {
+		return cb(cacheMultiStore{branch, cms.stores})
+	})
+}
+
+// TracingEnabled implements types.MultiStore.
+func (mv *MultiMVMemoryView) TracingEnabled() bool {
+	return mv.traceWriter != nil
+}
+
+// SetTracer implements types.MultiStore.
+func (mv *MultiMVMemoryView) SetTracer(w io.Writer) storetypes.MultiStore {
+	mv.traceWriter = w
+	return mv
+}
+
+// SetTracingContext implements types.MultiStore, the context is merged with the existing one.
+func (mv *MultiMVMemoryView) SetTracingContext(tc storetypes.TraceContext) storetypes.MultiStore {
+	mv.traceContext = mv.traceContext.Merge(tc)
+	return mv
+}
+
+func (mv *MultiMVMemoryView) storeTraceContext(name storetypes.StoreKey) storetypes.TraceContext {
+	return mv.traceContext.Clone().Merge(storetypes.TraceContext{
+		storeNameCtxKey: name.Name(),
+	})
+}
+
+// Checkpoint records the current write sets of all the stores, returns an id to be used in `RevertTo`.
+func (mv *MultiMVMemoryView) Checkpoint() int {
+	checkpoint := make(map[storetypes.StoreKey]any, len(mv.views))
+	for key, view := range mv.views {
+		checkpoint[key] = view.CheckpointWriteSet()
+	}
+	mv.checkpoints = append(mv.checkpoints, checkpoint)
+	return len(mv.checkpoints) - 1
+}
+
+// RevertTo discards the writes made after the checkpoint `id` in all the stores,
+// the checkpoint and the ones after it are invalidated, the read sets are not affected.
+func (mv *MultiMVMemoryView) RevertTo(id int) {
+	if id < 0 || id >= len(mv.checkpoints) {
+		panic(fmt.Sprintf("invalid checkpoint id: %d", id))
+	}
+
+	checkpoint := mv.checkpoints[id]
+	for key, view := range mv.views {
+		// views initialized after the checkpoint are reverted to empty write set
+		view.RevertWriteSet(checkpoint[key])
+	}
+	mv.checkpoints = mv.checkpoints[:id]
+}
+
 func (mv *MultiMVMemoryView) ReadSet() *MultiReadSet {
 	rs := make(MultiReadSet, len(mv.views))
 	for key, view := range mv.views {
@@ -0,0 +1,110 @@
+package block_stm
+
+import (
+	"bytes"
+	"testing"
+
+	storetypes "cosmossdk.io/store/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestMultiMVMemoryViewCacheMultiStore(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	storage := NewMultiMemDB(stores)
+	storage.GetKVStore(StoreKeyBank).Set(Key("b"), []byte("1"))
+	mv := NewMVMemory(16, stores, storage, nil)
+
+	mview := mv.View(0)
+	var ms storetypes.MultiStore = mview
+	require.Equal(t, storetypes.StoreTypeMulti, ms.GetStoreType())
+
+	cache := ms.CacheMultiStore()
+	cache.GetKVStore(StoreKeyAuth).Set(Key("a"), []byte("1"))
+	cache.GetKVStore(StoreKeyBank).Delete(Key("b"))
+	require.Equal(t, []byte("1"), cache.GetKVStore(StoreKeyAuth).Get(Key("a")))
+
+	// not visible before write
+	require.Nil(t, mview.GetKVStore(StoreKeyAuth).Get(Key("a")))
+	require.Equal(t, []byte("1"), mview.GetKVStore(StoreKeyBank).Get(Key("b")))
+
+	cache.Write()
+	require.Equal(t, []byte("1"), mview.GetKVStore(StoreKeyAuth).Get(Key("a")))
+	require.Nil(t, mview.GetKVStore(StoreKeyBank).Get(Key("b")))
+
+	// discarded branch don't touch the write set
+	cache = ms.CacheMultiStore()
+	cache.GetKVStore(StoreKeyAuth).Set(Key("c"), []byte("1"))
+	require.Nil(t, mview.GetKVStore(StoreKeyAuth).Get(Key("c")))
+
+	require.True(t, mv.Record(TxnVersion{0, 0}, mview))
+	mv.WriteSnapshot(storage)
+	require.Equal(t, []byte("1"), storage.GetKVStore(StoreKeyAuth).Get(Key("a")))
+	require.Nil(t, storage.GetKVStore(StoreKeyAuth).Get(Key("c")))
+	require.Nil(t, storage.GetKVStore(StoreKeyBank).Get(Key("b")))
+}
+
+func TestMultiMVMemoryViewTracing(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0}
+	storage := NewMultiMemDB(stores)
+	mv := NewMVMemory(16, stores, storage, nil)
+
+	var buf bytes.Buffer
+	ms := storetypes.MultiStore(mv.View(0)).SetTracer(&buf)
+	ms = ms.SetTracingContext(storetypes.TraceContext{"txn": 0})
+	require.True(t, ms.TracingEnabled())
+
+	ms.GetKVStore(StoreKeyAuth).Set(Key("a"), []byte("1"))
+	require.Contains(t, buf.String(), `"store_name":"acc"`)
+	require.Contains(t, buf.String(), `"txn":0`)
+}
+
+func TestMultiMVMemoryViewCheckpoint(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	storage := NewMultiMemDB(stores)
+	storage.GetKVStore(StoreKeyBank).Set(Key("x"), []byte("0"))
+	mv := NewMVMemory(16, stores, storage, nil)
+
+	mview := mv.View(0)
+	auth := mview.GetKVStore(StoreKeyAuth)
+	auth.Set(Key("fee"), []byte("1"))
+
+	id := mview.Checkpoint()
+	auth.Set(Key("fee"), []byte("2"))
+	auth.Set(Key("a"), []byte("1"))
+	// the bank view is initialized after the checkpoint
+	bank := mview.GetKVStore(StoreKeyBank)
+	require.Equal(t, []byte("0"), bank.Get(Key("x")))
+	bank.Set(Key("x"), []byte("1"))
+
+	id2 := mview.Checkpoint()
+	auth.Delete(Key("a"))
+	mview.RevertTo(id2)
+	require.Equal(t, []byte("1"), auth.Get(Key("a")))
+
+	mview.RevertTo(id)
+	require.Equal(t, []byte("1"), auth.Get(Key("fee")))
+	require.Nil(t, auth.Get(Key("a")))
+	require.Equal(t, []byte("0"), bank.Get(Key("x")))
+	require.Panics(t, func() { mview.RevertTo(id2) })
+
+	// read of the reverted store is still tracked
+	rs := *mview.ReadSet()
+	require.Equal(t, Key("x"), rs[1].Reads[0].Key)
+
+	require.True(t, mv.Record(TxnVersion{0, 0}, mview))
+	mv.WriteSnapshot(storage)
+	require.Equal(t, []byte("1"), storage.GetKVStore(StoreKeyAuth).Get(Key("fee")))
+	require.Nil(t, storage.GetKVStore(StoreKeyAuth).Get(Key("a")))
+	require.Equal(t, []byte("0"), storage.GetKVStore(StoreKeyBank).Get(Key("x")))
+}
+
+func TestMultiMVMemoryViewUnknownStore(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0}
+	storage := NewMultiMemDB(stores)
+	mv := NewMVMemory(16, stores, storage, nil)
+
+	mview := mv.View(0)
+	require.Equal(t, ErrUnknownStore{Store: "bank"}, recoverPanic(func() { mview.GetKVStore(StoreKeyBank) }))
+	require.Equal(t, ErrUnknownStore{Store: "bank"}, recoverPanic(func() { mv.ViewAt(0).GetKVStore(StoreKeyBank) }))
+	require.Equal(t, "unknown store bank, it must be included in the stores of the block", ErrUnknownStore{Store: "bank"}.Error())
+}
@@ -15,22 +15,25 @@ const (
 type MVData = GMVData[[]byte]
 
 func NewMVData() *MVData {
-	return NewGMVData(BytesIsZero, BytesLen)
+	return BytesValueType.NewMVData()
 }
 
 type GMVData[V any] struct {
 	BTree[dataItem[V]]
 	isZero   func(V) bool
 	valueLen func(V) int
+	// optional, see `ValueType.Clone`, `ValueType.Equal` and `ValueType.Fingerprint`
+	clone          func(V) V
+	cloneByDefault bool
+	equal          func(V, V) bool
+	fingerprint    func(V) uint64
+	marshal        func(V) []byte
 }
 
+// NewMVStore creates the multi-version store with the value type registered for the store key type,
+// see `RegisterValueType`.
 func NewMVStore(key storetypes.StoreKey) MVStore {
-	switch key.(type) {
-	case *storetypes.ObjectStoreKey:
-		return NewGMVData(ObjIsZero, ObjLen)
-	default:
-		return NewGMVData(BytesIsZero, BytesLen)
-	}
+	return lookupStoreType(key).NewMVStore()
 }
 
 func NewGMVData[V any](isZero func(V) bool, valueLen func(V) int) *GMVData[V] {
@@ -95,6 +98,77 @@ func (d *GMVData[V]) Read(key Key, txn TxnIndex) (V, TxnVersion, bool) {
 	return item.Value, item.Version(), item.Estimate
 }
 
+// ReadTxn returns the value written by the txn itself, returns `false` if not found or it's an estimate.
+func (d *GMVData[V]) ReadTxn(key Key, txn TxnIndex) (V, bool) {
+	var zero V
+	tree := d.getTree(key)
+	if tree == nil {
+		return zero, false
+	}
+
+	item, ok := tree.Get(secondaryDataItem[V]{Index: txn})
+	if !ok || item.Estimate {
+		return zero, false
+	}
+	return item.Value, true
+}
+
+// TxnWrites returns the values written by the txn at the locations, the values are boxed to be value type agnostic,
+// `nil` value means deleted.
+func (d *GMVData[V]) TxnWrites(txn TxnIndex, locations Locations) []GKVPair[any] {
+	pairs := make([]GKVPair[any], 0, len(locations))
+	for _, key := range locations {
+		value, ok := d.ReadTxn(key, txn)
+		if !ok {
+			continue
+		}
+
+		var boxed any
+		if !d.isZero(value) {
+			boxed = value
+		}
+		pairs = append(pairs, GKVPair[any]{key, boxed})
+	}
+	return pairs
+}
+
+// MarshalValue encodes a value boxed by `TxnWrites`, `nil` means deleted, panics if the value type has no
+// `ValueType.Marshal`.
+func (d *GMVData[V]) MarshalValue(value any) []byte {
+	if value == nil {
+		return nil
+	}
+	return mustMarshal(d.marshal)(value.(V))
+}
+
+// SnapshotMarshaled calls the callback with the encoded snapshot, `nil` value means deleted, panics if the value type
+// has no `ValueType.Marshal`.
+func (d *GMVData[V]) SnapshotMarshaled(cb func(Key, []byte) bool) {
+	marshal := mustMarshal(d.marshal)
+	d.SnapshotTo(func(key Key, value V) bool {
+		if d.isZero(value) {
+			return cb(key, nil)
+		}
+		return cb(key, marshal(value))
+	})
+}
+
+// CopyTxnWrites copies the values written by txn `from` in `src` at the locations as the version `to`,
+// `src` must have the same value type, returns the copied locations.
+func (d *GMVData[V]) CopyTxnWrites(src MVStore, from TxnIndex, to TxnVersion, locations Locations) Locations {
+	data := src.(*GMVData[V])
+	copied := make(Locations, 0, len(locations))
+	for _, key := range locations {
+		value, ok := data.ReadTxn(key, from)
+		if !ok {
+			continue
+		}
+		d.Write(key, value, to)
+		copied = append(copied, key)
+	}
+	return copied
+}
+
 func (d *GMVData[V]) Iterator(
 	opts IteratorOptions, txn TxnIndex,
 	waitFn func(TxnIndex),
@@ -105,26 +179,31 @@ func (d *GMVData[V]) Iterator(
 // ValidateReadSet validates the read descriptors,
 // returns true if valid.
 func (d *GMVData[V]) ValidateReadSet(txn TxnIndex, rs *ReadSet) bool {
+	return d.FindConflict(txn, rs) == nil
+}
+
+// FindConflict returns the first invalid read in the read set, or nil if valid, the store index is not filled.
+func (d *GMVData[V]) FindConflict(txn TxnIndex, rs *ReadSet) *ReadConflict {
 	for _, desc := range rs.Reads {
 		_, version, estimate := d.Read(desc.Key, txn)
 		if estimate {
 			// previously read entry from data, now ESTIMATE
-			return false
+			return &ReadConflict{Reason: AbortReadEstimate, Key: desc.Key}
 		}
 		if version != desc.Version {
 			// previously read entry from data, now NOT_FOUND,
 			// or read some entry, but not the same version as before
-			return false
+			return &ReadConflict{Reason: AbortReadChanged, Key: desc.Key}
 		}
 	}
 
 	for _, desc := range rs.Iterators {
 		if !d.validateIterator(desc, txn) {
-			return false
+			return &ReadConflict{Reason: AbortIteratorChanged, Key: desc.Start}
 		}
 	}
 
-	return true
+	return nil
 }
 
 // validateIterator validates the iteration descriptor by replaying and compare the recorded reads.
@@ -184,9 +263,18 @@ func (d *GMVData[V]) SnapshotTo(cb func(Key, V) bool) {
 	})
 }
 
-func (d *GMVData[V]) SnapshotToStore(store storetypes.Store) {
+// SnapshotToStore writes the snapshot into the store, if `skipNoop` is set, it compares the values with the store
+// first, and skips the writes which don't change it, i.e. deleting an absent key, or setting an equal value, the latter
+// requires `ValueType.Equal`, returns the number of written and skipped keys.
+func (d *GMVData[V]) SnapshotToStore(store storetypes.Store, skipNoop bool) (written, skipped int) {
 	kv := store.(storetypes.GKVStore[V])
 	d.SnapshotTo(func(key Key, value V) bool {
+		if skipNoop && d.isNoopWrite(kv, key, value) {
+			skipped++
+			return true
+		}
+
+		written++
 		if d.isZero(value) {
 			kv.Delete(key)
 		} else {
@@ -194,6 +282,18 @@ func (d *GMVData[V]) SnapshotToStore(store storetypes.Store) {
 		}
 		return true
 	})
+	return
+}
+
+func (d *GMVData[V]) isNoopWrite(kv storetypes.GKVStore[V], key Key, value V) bool {
+	if d.isZero(value) {
+		return !kv.Has(key)
+	}
+	if d.equal == nil {
+		return false
+	}
+	old := kv.Get(key)
+	return !d.isZero(old) && d.equal(old, value)
 }
 
 type GKVPair[V any] struct {
@@ -232,3 +332,13 @@ func (item secondaryDataItem[V]) Version() TxnVersion {
 func seekClosestTxn[V any](tree *BTree[secondaryDataItem[V]], txn TxnIndex) (secondaryDataItem[V], bool) {
 	return tree.ReverseSeek(secondaryDataItem[V]{Index: txn - 1})
 }
+
+func (d *GMVData[V]) NewMVView(
+	store int, storage storetypes.Store, scheduler *Scheduler, txn TxnIndex, opts ViewOptions,
+) MVView {
+	return NewGMVMemoryView(store, storage.(storetypes.GKVStore[V]), d, scheduler, txn, opts)
+}
+
+func (d *GMVData[V]) NewReadOnlyView(storage storetypes.Store, txn TxnIndex) storetypes.Store {
+	return NewGReadOnlyView(storage.(storetypes.GKVStore[V]), d, txn)
+}
@@ -103,9 +103,43 @@ func TestSnapshot(t *testing.T) {
 		{[]byte("d"), nil},
 	}, data.Snapshot())
 
-	data.SnapshotToStore(storage)
+	data.SnapshotToStore(storage, false)
 	require.Equal(t, []byte("3"), storage.Get([]byte("a")))
 	require.Equal(t, []byte("2"), storage.Get([]byte("b")))
 	require.Nil(t, storage.Get([]byte("d")))
 	require.Equal(t, 2, storage.Len())
 }
+
+func TestSnapshotToStoreSkipNoop(t *testing.T) {
+	storage := NewMemDB()
+	storage.Set([]byte("a"), []byte("0"))
+	storage.Set([]byte("b"), []byte("0"))
+	storage.Set([]byte("c"), []byte("0"))
+
+	data := NewMVData()
+	data.Write([]byte("a"), []byte("0"), TxnVersion{Index: 1, Incarnation: 1})
+	data.Write([]byte("b"), []byte("1"), TxnVersion{Index: 1, Incarnation: 1})
+	data.Write([]byte("c"), nil, TxnVersion{Index: 1, Incarnation: 1})
+	data.Write([]byte("d"), nil, TxnVersion{Index: 1, Incarnation: 1})
+	data.Write([]byte("e"), []byte("1"), TxnVersion{Index: 1, Incarnation: 1})
+
+	// "a" is unchanged, "d" is absent
+	written, skipped := data.SnapshotToStore(storage, true)
+	require.Equal(t, 3, written)
+	require.Equal(t, 2, skipped)
+	require.Equal(t, []byte("0"), storage.Get([]byte("a")))
+	require.Equal(t, []byte("1"), storage.Get([]byte("b")))
+	require.Nil(t, storage.Get([]byte("c")))
+	require.Equal(t, []byte("1"), storage.Get([]byte("e")))
+	require.Equal(t, 3, storage.Len())
+
+	// the object values are incomparable, only the deletions are skipped
+	objStorage := NewObjMemDB()
+	objStorage.Set([]byte("a"), "0")
+	objData := ObjValueType.NewMVData()
+	objData.Write([]byte("a"), "0", TxnVersion{Index: 1, Incarnation: 1})
+	objData.Write([]byte("b"), nil, TxnVersion{Index: 1, Incarnation: 1})
+	written, skipped = objData.SnapshotToStore(objStorage, true)
+	require.Equal(t, 1, written)
+	require.Equal(t, 1, skipped)
+}
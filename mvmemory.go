@@ -1,6 +1,8 @@
 package block_stm
 
 import (
+	"fmt"
+	"sync"
 	"sync/atomic"
 
 	storetypes "cosmossdk.io/store/types"
@@ -20,6 +22,52 @@ type MVMemory struct {
 	data                 []MVStore
 	lastWrittenLocations []atomic.Pointer[MultiLocations]
 	lastReadSet          []atomic.Pointer[MultiReadSet]
+	viewOptions          ViewOptions
+
+	// txn -> the `ErrUnknownStore` raised by the last execution
+	txErrors []atomic.Pointer[ErrUnknownStore]
+
+	// abortsMtx protects aborts, txn -> the conflicts which aborted each incarnation, only recorded for the trace.
+	abortsMtx sync.Mutex
+	aborts    [][]*ReadConflict
+}
+
+// ValidateStores checks the stores argument of `ExecuteBlock`, the indexes must be a permutation of
+// `0..len(stores)-1`, the names must be unique, and the parent storage must contain all the stores, the missing ones
+// are reported as `ErrUnknownStore`, whether the storage returns `nil` or panics, like the SDK multi-stores.
+func ValidateStores(stores map[storetypes.StoreKey]int, storage MultiStore) error {
+	indexes := make([]storetypes.StoreKey, len(stores))
+	names := make(map[string]struct{}, len(stores))
+	for key, i := range stores {
+		if i < 0 || i >= len(stores) {
+			return fmt.Errorf("index %d of store %s is out of range [0, %d)", i, key.Name(), len(stores))
+		}
+		if indexes[i] != nil {
+			return fmt.Errorf("stores %s and %s have the same index %d", indexes[i].Name(), key.Name(), i)
+		}
+		indexes[i] = key
+
+		if _, ok := names[key.Name()]; ok {
+			return fmt.Errorf("duplicated store name %s", key.Name())
+		}
+		names[key.Name()] = struct{}{}
+	}
+	for _, key := range indexes {
+		if !hasStore(storage, key) {
+			return ErrUnknownStore{Store: key.Name(), Parent: true}
+		}
+	}
+	return nil
+}
+
+// hasStore returns false if the storage returns `nil` or panics for the store.
+func hasStore(storage MultiStore, key storetypes.StoreKey) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return storage.GetStore(key) != nil
 }
 
 func NewMVMemory(
@@ -45,6 +93,8 @@ func NewMVMemoryWithEstimates(
 		data:                 data,
 		lastWrittenLocations: make([]atomic.Pointer[MultiLocations], block_size),
 		lastReadSet:          make([]atomic.Pointer[MultiReadSet], block_size),
+		txErrors:             make([]atomic.Pointer[ErrUnknownStore], block_size),
+		viewOptions:          DefaultViewOptions(),
 	}
 
 	// init with pre-estimates
@@ -56,6 +106,33 @@ func NewMVMemoryWithEstimates(
 	return mv
 }
 
+// ExecuteTx runs the transaction on the view, the `ErrUnknownStore` panic is recovered and recorded, so the block
+// finishes normally and `Err` returns it, the other panics are propagated.
+func (mv *MVMemory) ExecuteTx(txExecutor TxExecutor, txn TxnIndex, view MultiStore) {
+	mv.txErrors[txn].Store(nil)
+	defer func() {
+		if r := recover(); r != nil {
+			err, ok := r.(ErrUnknownStore)
+			if !ok {
+				panic(r)
+			}
+			mv.txErrors[txn].Store(&err)
+		}
+	}()
+	txExecutor(txn, view)
+}
+
+// Err returns the `ErrUnknownStore` raised by the final execution of the lowest transaction, should only be called
+// after the block is executed.
+func (mv *MVMemory) Err() error {
+	for txn := range mv.txErrors {
+		if err := mv.txErrors[txn].Load(); err != nil {
+			return fmt.Errorf("txn %d: %w", txn, *err)
+		}
+	}
+	return nil
+}
+
 func (mv *MVMemory) Record(version TxnVersion, view *MultiMVMemoryView) bool {
 	newLocations := view.ApplyWriteSet(version)
 	wroteNewLocation := mv.rcuUpdateWrittenLocations(version.Index, newLocations)
@@ -111,14 +188,53 @@ func (mv *MVMemory) ConvertWritesToEstimates(txn TxnIndex) {
 }
 
 func (mv *MVMemory) ValidateReadSet(txn TxnIndex) bool {
+	return mv.FindConflict(txn) == nil
+}
+
+// FindConflict returns the first invalid read of the transaction, or nil if the read set is valid.
+func (mv *MVMemory) FindConflict(txn TxnIndex) *ReadConflict {
 	// Invariant: at least one `Record` call has been made for `txn`
 	rs := *mv.lastReadSet[txn].Load()
 	for store, readSet := range rs {
-		if !mv.data[store].ValidateReadSet(txn, readSet) {
-			return false
+		if conflict := mv.data[store].FindConflict(txn, readSet); conflict != nil {
+			conflict.Store = store
+			return conflict
 		}
 	}
-	return true
+	return nil
+}
+
+// EnableAbortTrace starts recording the conflicts which abort the incarnations, see `TxnTrace.Incarnations`.
+func (mv *MVMemory) EnableAbortTrace() {
+	mv.abortsMtx.Lock()
+	defer mv.abortsMtx.Unlock()
+	if mv.aborts == nil {
+		mv.aborts = make([][]*ReadConflict, mv.BlockSize())
+	}
+}
+
+// RecordAbort records the conflict which aborted the incarnation, if the trace is enabled.
+func (mv *MVMemory) RecordAbort(version TxnVersion, conflict *ReadConflict) {
+	mv.abortsMtx.Lock()
+	defer mv.abortsMtx.Unlock()
+	if mv.aborts == nil {
+		return
+	}
+	aborts := mv.aborts[version.Index]
+	for len(aborts) <= int(version.Incarnation) {
+		aborts = append(aborts, nil)
+	}
+	aborts[version.Incarnation] = conflict
+	mv.aborts[version.Index] = aborts
+}
+
+func (mv *MVMemory) abortConflict(version TxnVersion) *ReadConflict {
+	mv.abortsMtx.Lock()
+	defer mv.abortsMtx.Unlock()
+	if mv.aborts == nil || int(version.Incarnation) >= len(mv.aborts[version.Index]) {
+		return nil
+	}
+	return mv.aborts[version.Index][version.Incarnation]
 }
 
 func (mv *MVMemory) readLastWrittenLocations(txn TxnIndex) MultiLocations {
@@ -129,10 +245,9 @@ func (mv *MVMemory) readLastWrittenLocations(txn TxnIndex) MultiLocations {
 	return nil
 }
 
-func (mv *MVMemory) WriteSnapshot(storage MultiStore) {
-	for name, i := range mv.stores {
-		mv.data[i].SnapshotToStore(storage.GetStore(name))
-	}
+// BlockSize returns the number of transactions in the block.
+func (mv *MVMemory) BlockSize() TxnIndex {
+	return TxnIndex(len(mv.lastWrittenLocations))
 }
 
 // View creates a view for a particular transaction.
@@ -142,7 +257,12 @@ func (mv *MVMemory) View(txn TxnIndex) *MultiMVMemoryView {
 
 func (mv *MVMemory) newMVView(name storetypes.StoreKey, txn TxnIndex) MVView {
 	i := mv.stores[name]
-	return NewMVView(i, mv.storage.GetStore(name), mv.GetMVStore(i), mv.scheduler, txn)
+	return NewMVView(i, mv.storage.GetStore(name), mv.GetMVStore(i), mv.scheduler, txn, mv.viewOptions)
+}
+
+// SetViewOptions configures the views created afterwards.
+func (mv *MVMemory) SetViewOptions(opts ViewOptions) {
+	mv.viewOptions = opts
 }
 
 func (mv *MVMemory) GetMVStore(i int) MVStore {
@@ -1,6 +1,9 @@
 package block_stm
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
 
 	storetypes "cosmossdk.io/store/types"
@@ -216,3 +219,113 @@ func TestMVMemoryDelete(t *testing.T) {
 		require.Equal(t, []byte{0}, bankStore.Get(balanceKey))
 	}
 }
+
+func TestValidateStores(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	storage := NewMultiMemDB(stores)
+	require.NoError(t, ValidateStores(stores, storage))
+
+	testCases := []struct {
+		stores map[storetypes.StoreKey]int
+		err    string
+	}{
+		{map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 2}, "index 2 of store bank is out of range [0, 2)"},
+		{map[storetypes.StoreKey]int{StoreKeyAuth: -1}, "index -1 of store acc is out of range [0, 1)"},
+		{map[storetypes.StoreKey]int{StoreKeyAuth: 0, storetypes.NewKVStoreKey("acc"): 1}, "duplicated store name acc"},
+		{map[storetypes.StoreKey]int{StoreKeyAuth: 0, storetypes.NewKVStoreKey("mint"): 1}, "store mint is missing in the parent storage"},
+	}
+	for _, tc := range testCases {
+		require.EqualError(t, ValidateStores(tc.stores, storage), tc.err)
+	}
+
+	// same index
+	err := ValidateStores(map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 0}, storage)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "have the same index 0")
+
+	// rejected before execution
+	err = ExecuteBlock(context.Background(), 1, map[storetypes.StoreKey]int{StoreKeyAuth: 1}, storage, 1, func(TxnIndex, MultiStore) {})
+	require.EqualError(t, err, "index 1 of store acc is out of range [0, 1)")
+
+	// the SDK multi-stores panic on the missing stores
+	err = ValidateStores(map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}, panickingMultiStore{NewMultiMemDB(map[storetypes.StoreKey]int{StoreKeyAuth: 0})})
+	require.Equal(t, ErrUnknownStore{Store: "bank", Parent: true}, err)
+}
+
+// panickingMultiStore panics on the missing stores like the SDK multi-stores.
+type panickingMultiStore struct {
+	*MultiMemDB
+}
+
+func (ms panickingMultiStore) GetStore(key storetypes.StoreKey) storetypes.Store {
+	store := ms.MultiMemDB.GetStore(key)
+	if store == nil {
+		panic(fmt.Sprintf("store %s is not registered", key.Name()))
+	}
+	return store
+}
+
+func TestExecuteBlockUnknownStore(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0}
+	storage := NewMultiMemDB(stores)
+	executeTx := func(txn TxnIndex, store MultiStore) {
+		store.GetKVStore(StoreKeyAuth).Set(Key{byte(txn)}, []byte("1"))
+		if txn == 3 {
+			store.GetKVStore(StoreKeyBank).Get(Key("a"))
+		}
+	}
+
+	err := ExecuteBlock(context.Background(), 10, stores, storage, 4, executeTx)
+	require.EqualError(t, err, "txn 3: unknown store bank, it must be included in the stores of the block")
+	var unknown ErrUnknownStore
+	require.True(t, errors.As(err, &unknown))
+	require.Equal(t, "bank", unknown.Store)
+	// not committed
+	require.Nil(t, storage.GetKVStore(StoreKeyAuth).Get(Key{0}))
+
+	accesses := make([]TxnAccess, 10)
+	err = ExecuteBlockValidateOnly(context.Background(), 10, stores, storage, 4, accesses, executeTx)
+	require.EqualError(t, err, "txn 3: unknown store bank, it must be included in the stores of the block")
+	require.Nil(t, storage.GetKVStore(StoreKeyAuth).Get(Key{0}))
+}
+
+func TestExecuteBlockUnknownStoreInBranch(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0}
+	testCases := []struct {
+		name string
+		read func(storetypes.MultiStore)
+	}{
+		{"CacheMultiStore", func(store storetypes.MultiStore) {
+			store.CacheMultiStore().GetKVStore(StoreKeyBank)
+		}},
+		{"nested", func(store storetypes.MultiStore) {
+			store.CacheMultiStore().CacheMultiStore().GetStore(StoreKeyBank)
+		}},
+		{"CacheWrap", func(store storetypes.MultiStore) {
+			store.CacheWrap().(storetypes.CacheMultiStore).GetObjKVStore(StoreKeyBank)
+		}},
+		{"RunAtomic", func(store storetypes.MultiStore) {
+			_ = store.CacheMultiStore().RunAtomic(func(cms storetypes.CacheMultiStore) error {
+				cms.GetKVStore(StoreKeyBank)
+				return nil
+			})
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			storage := NewMultiMemDB(stores)
+			err := ExecuteBlock(context.Background(), 4, stores, storage, 2, func(txn TxnIndex, store MultiStore) {
+				ms := store.(storetypes.MultiStore)
+				cms := ms.CacheMultiStore()
+				cms.GetKVStore(StoreKeyAuth).Set(Key{byte(txn)}, []byte("1"))
+				cms.Write()
+				if txn == 2 {
+					tc.read(ms)
+				}
+			})
+			require.Equal(t, ErrUnknownStore{Store: "bank"}, errors.Unwrap(err))
+			require.EqualError(t, err, "txn 2: unknown store bank, it must be included in the stores of the block")
+			require.Nil(t, storage.GetKVStore(StoreKeyAuth).Get(Key{0}))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package block_stm
 
 import (
+	"bytes"
 	"io"
 
 	"cosmossdk.io/store/cachekv"
@@ -25,20 +26,22 @@ type GMVMemoryView[V any] struct {
 	txn      TxnIndex
 	readSet  *ReadSet
 	writeSet *GMemDB[V]
+
+	opts ViewOptions
+	// the shared values observed and the values written by the txn, only recorded in strict mode
+	fingerprints      []valueFingerprint[V]
+	writeFingerprints []valueFingerprint[V]
 }
 
-func NewMVView(store int, storage storetypes.Store, mvData MVStore, scheduler *Scheduler, txn TxnIndex) MVView {
-	switch data := mvData.(type) {
-	case *GMVData[any]:
-		return NewGMVMemoryView(store, storage.(storetypes.ObjKVStore), data, scheduler, txn)
-	case *GMVData[[]byte]:
-		return NewGMVMemoryView(store, storage.(storetypes.KVStore), data, scheduler, txn)
-	default:
-		panic("unsupported value type")
-	}
+func NewMVView(
+	store int, storage storetypes.Store, mvData MVStore, scheduler *Scheduler, txn TxnIndex, opts ViewOptions,
+) MVView {
+	return mvData.NewMVView(store, storage, scheduler, txn, opts)
 }
 
-func NewGMVMemoryView[V any](store int, storage storetypes.GKVStore[V], mvData *GMVData[V], scheduler *Scheduler, txn TxnIndex) *GMVMemoryView[V] {
+func NewGMVMemoryView[V any](
+	store int, storage storetypes.GKVStore[V], mvData *GMVData[V], scheduler *Scheduler, txn TxnIndex, opts ViewOptions,
+) *GMVMemoryView[V] {
 	return &GMVMemoryView[V]{
 		store:     store,
 		storage:   storage,
@@ -46,6 +49,7 @@ func NewGMVMemoryView[V any](store int, storage storetypes.GKVStore[V], mvData *
 		scheduler: scheduler,
 		txn:       txn,
 		readSet:   new(ReadSet),
+		opts:      opts,
 	}
 }
 
@@ -63,6 +67,9 @@ func (s *GMVMemoryView[V]) waitFor(txn TxnIndex) {
 }
 
 func (s *GMVMemoryView[V]) ApplyWriteSet(version TxnVersion) Locations {
+	if s.opts.Strict {
+		s.verifyFingerprints()
+	}
 	if s.writeSet == nil || s.writeSet.Len() == 0 {
 		return nil
 	}
@@ -81,6 +88,33 @@ func (s *GMVMemoryView[V]) ReadSet() *ReadSet {
 	return s.readSet
 }
 
+// writeSetCheckpoint is the opaque snapshot returned by `CheckpointWriteSet`.
+type writeSetCheckpoint[V any] struct {
+	writeSet          *GMemDB[V]
+	writeFingerprints int
+}
+
+// CheckpointWriteSet returns a copy-on-write clone of the write set, `nil` if nothing is written yet.
+func (s *GMVMemoryView[V]) CheckpointWriteSet() any {
+	if s.writeSet == nil {
+		return nil
+	}
+	return writeSetCheckpoint[V]{s.writeSet.Copy(), len(s.writeFingerprints)}
+}
+
+// RevertWriteSet restores the write set to a checkpoint returned by `CheckpointWriteSet`,
+// the reads are kept in the read set because they still matter for validation.
+func (s *GMVMemoryView[V]) RevertWriteSet(checkpoint any) {
+	if checkpoint == nil {
+		s.writeSet = nil
+		s.writeFingerprints = s.writeFingerprints[:0]
+		return
+	}
+	cp := checkpoint.(writeSetCheckpoint[V])
+	s.writeSet = cp.writeSet
+	s.writeFingerprints = s.writeFingerprints[:cp.writeFingerprints]
+}
+
 func (s *GMVMemoryView[V]) Get(key []byte) V {
 	if s.writeSet != nil {
 		if value, found := s.writeSet.OverlayGet(key); found {
@@ -102,9 +136,9 @@ func (s *GMVMemoryView[V]) Get(key []byte) V {
 		// if not found, record version ⊥ when reading from storage.
 		s.readSet.Reads = append(s.readSet.Reads, ReadDescriptor{key, version})
 		if !version.Valid() {
-			return s.storage.Get(key)
+			value = s.storage.Get(key)
 		}
-		return value
+		return s.sharedValue(key, value)
 	}
 }
 
@@ -116,14 +150,28 @@ func (s *GMVMemoryView[V]) Set(key []byte, value V) {
 	if s.mvData.isZero(value) {
 		panic("nil value is not allowed")
 	}
+	if s.opts.CopyOnWrite {
+		key, value = bytes.Clone(key), s.cloneValue(value)
+	} else if s.cloneByDefault() {
+		value = s.cloneValue(value)
+	}
 	s.init()
 	s.writeSet.OverlaySet(key, value)
+	if s.opts.Strict {
+		s.fingerprintWrite(key, value)
+	}
 }
 
 func (s *GMVMemoryView[V]) Delete(key []byte) {
 	var empty V
+	if s.opts.CopyOnWrite {
+		key = bytes.Clone(key)
+	}
 	s.init()
 	s.writeSet.OverlaySet(key, empty)
+	if s.opts.Strict {
+		s.fingerprintWrite(key, empty)
+	}
 }
 
 func (s *GMVMemoryView[V]) Iterator(start, end []byte) storetypes.GIterator[V] {
@@ -158,13 +206,10 @@ func (s *GMVMemoryView[V]) iterator(opts IteratorOptions) storetypes.GIterator[V
 		if iter.Valid() {
 			stopKey = iter.Key()
 
-			// if the iterator is not exhausted, the merge iterator may have read one more key which is not observed by
-			// the caller, in that case we remove that read descriptor.
-			if len(reads) > 0 {
-				lastRead := reads[len(reads)-1].Key
-				if BytesBeyond(lastRead, stopKey, opts.Ascending) {
-					reads = reads[:len(reads)-1]
-				}
+			// if the iterator is not exhausted, the merge iterator may have read more keys which are not observed by
+			// the caller, e.g. skipped the deleted ones ahead, in that case we remove those read descriptors.
+			for len(reads) > 0 && BytesBeyond(reads[len(reads)-1].Key, stopKey, opts.Ascending) {
+				reads = reads[:len(reads)-1]
 			}
 		}
 
@@ -176,13 +221,17 @@ func (s *GMVMemoryView[V]) iterator(opts IteratorOptions) storetypes.GIterator[V
 	}
 
 	// three-way merge iterator
-	return NewCacheMergeIterator(
+	iter := NewCacheMergeIterator(
 		NewCacheMergeIterator(parentIter, mvIter, opts.Ascending, nil, s.mvData.isZero),
 		wsIter,
 		opts.Ascending,
 		onClose,
 		s.mvData.isZero,
 	)
+	if s.opts.Strict || s.opts.CopyOnRead || s.cloneByDefault() {
+		iter = &sharedValueIterator[V]{GIterator: iter, view: s}
+	}
+	return iter
 }
 
 // CacheWrap implements types.Store.
@@ -170,6 +170,171 @@ func TestMVMemoryViewIteration(t *testing.T) {
 	}
 }
 
+func TestMVMemoryViewIteratorStopAfterDeleted(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0}
+	storage := NewMultiMemDB(stores)
+	mv := NewMVMemory(16, stores, storage, nil)
+
+	mview := mv.View(0)
+	view := mview.GetKVStore(StoreKeyAuth)
+	view.Set(Key("b"), []byte("1"))
+	view.Delete(Key("d"))
+	require.True(t, mv.Record(TxnVersion{0, 0}, mview))
+
+	// the merge iterator skips the deleted "d" and reads "b" ahead, both are not observed by the caller.
+	mview = mv.View(1)
+	view = mview.GetKVStore(StoreKeyAuth)
+	view.Set(Key("e"), []byte("1"))
+	iter := view.ReverseIterator(nil, nil)
+	require.Equal(t, Key("e"), Key(iter.Key()))
+	require.NoError(t, iter.Close())
+	mv.Record(TxnVersion{1, 0}, mview)
+
+	rs := *mv.lastReadSet[1].Load()
+	require.Empty(t, rs[0].Iterators[0].Reads)
+	require.True(t, mv.ValidateReadSet(1))
+}
+
+var StoreKeyObj = storetypes.NewObjectStoreKey("obj")
+
+// counterObj is a mutable object value implementing `Cloner`.
+type counterObj struct {
+	n int
+}
+
+func (c *counterObj) Clone() any {
+	return &counterObj{c.n}
+}
+
+func TestMVMemoryViewObjDelete(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyObj: 0}
+	storage := NewMultiMemDB(stores)
+	mv := NewMVMemory(16, stores, storage, nil)
+
+	mview := mv.View(0)
+	view := mview.GetObjKVStore(StoreKeyObj)
+	view.Set(Key("a"), &counterObj{1})
+	view.Set(Key("b"), &counterObj{1})
+	require.True(t, mv.Record(TxnVersion{0, 0}, mview))
+
+	mview = mv.View(1)
+	view = mview.GetObjKVStore(StoreKeyObj)
+	view.Delete(Key("a"))
+	view.Set(Key("b"), &counterObj{2})
+	require.True(t, mv.Record(TxnVersion{1, 0}, mview))
+
+	view = mv.View(2).GetObjKVStore(StoreKeyObj)
+	require.Nil(t, view.Get(Key("a")))
+	require.False(t, view.Has(Key("a")))
+	require.Equal(t, &counterObj{2}, view.Get(Key("b")))
+}
+
+func TestMVMemoryViewObjIteration(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyObj: 0}
+	storage := NewMultiMemDB(stores)
+	storage.GetObjKVStore(StoreKeyObj).Set(Key("A"), &counterObj{0})
+	mv := NewMVMemory(16, stores, storage, nil)
+
+	sets := [][]GKVPair[any]{
+		{{Key("a"), &counterObj{1}}, {Key("b"), &counterObj{1}}},
+		{{Key("b"), &counterObj{2}}, {Key("c"), &counterObj{2}}},
+	}
+	deletes := [][]Key{
+		{},
+		{Key("A"), Key("a")},
+	}
+	for i, pairs := range sets {
+		mview := mv.View(TxnIndex(i))
+		view := mview.GetObjKVStore(StoreKeyObj)
+		for _, kv := range pairs {
+			view.Set(kv.Key, kv.Value)
+		}
+		for _, key := range deletes[i] {
+			view.Delete(key)
+		}
+		require.True(t, mv.Record(TxnVersion{TxnIndex(i), 0}, mview))
+	}
+
+	view := mv.View(1).GetObjKVStore(StoreKeyObj)
+	iter := view.Iterator(nil, nil)
+	require.Equal(t, []GKVPair[any]{
+		{Key("A"), &counterObj{0}},
+		{Key("a"), &counterObj{1}},
+		{Key("b"), &counterObj{1}},
+	}, CollectIterator(iter))
+	require.NoError(t, iter.Close())
+
+	view = mv.View(2).GetObjKVStore(StoreKeyObj)
+	iter = view.ReverseIterator(nil, nil)
+	require.Equal(t, []GKVPair[any]{
+		{Key("c"), &counterObj{2}},
+		{Key("b"), &counterObj{2}},
+	}, CollectIterator(iter))
+	require.NoError(t, iter.Close())
+}
+
+func TestMVMemoryViewObjClone(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts ViewOptions
+	}{
+		{"default", ViewOptions{}},
+		{"copy", ViewOptions{CopyOnRead: true, CopyOnWrite: true}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			stores := map[storetypes.StoreKey]int{StoreKeyObj: 0}
+			storage := NewMultiMemDB(stores)
+			storage.GetObjKVStore(StoreKeyObj).Set(Key("p"), &counterObj{0})
+			mv := NewMVMemory(16, stores, storage, nil)
+			mv.SetViewOptions(tc.opts)
+
+			mview := mv.View(0)
+			view := mview.GetObjKVStore(StoreKeyObj)
+			obj := &counterObj{1}
+			view.Set(Key("a"), obj)
+			// copied on write, the caller is free to reuse the object
+			obj.n = 2
+			// the values not implementing `Cloner` are shared as is
+			shared := []int{1}
+			view.Set(Key("s"), shared)
+			require.True(t, mv.Record(TxnVersion{0, 0}, mview))
+
+			// mutate the values written by another txn, and of the parent storage
+			mview = mv.View(1)
+			view = mview.GetObjKVStore(StoreKeyObj)
+			view.Get(Key("a")).(*counterObj).n = 3
+			iter := view.Iterator(nil, nil)
+			for ; iter.Valid(); iter.Next() {
+				if c, ok := iter.Value().(*counterObj); ok {
+					c.n = 3
+				}
+			}
+			require.NoError(t, iter.Close())
+			view.Get(Key("s")).([]int)[0] = 3
+			mv.Record(TxnVersion{1, 0}, mview)
+
+			view = mv.View(2).GetObjKVStore(StoreKeyObj)
+			require.Equal(t, &counterObj{1}, view.Get(Key("a")))
+			require.Equal(t, &counterObj{0}, view.Get(Key("p")))
+			require.Equal(t, []int{3}, view.Get(Key("s")))
+		})
+	}
+}
+
+func TestMVMemoryViewObjZeroCopy(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyObj: 0}
+	mv := NewMVMemory(16, stores, NewMultiMemDB(stores), nil)
+	mv.SetViewOptions(ViewOptions{ZeroCopy: true})
+
+	mview := mv.View(0)
+	obj := &counterObj{1}
+	mview.GetObjKVStore(StoreKeyObj).Set(Key("a"), obj)
+	require.True(t, mv.Record(TxnVersion{0, 0}, mview))
+
+	// shared as is
+	require.True(t, obj == mv.View(1).GetObjKVStore(StoreKeyObj).Get(Key("a")))
+}
+
 func CollectIterator[V any](iter storetypes.GIterator[V]) []GKVPair[V] {
 	var res []GKVPair[V]
 	for iter.Valid() {
@@ -0,0 +1,121 @@
+package block_stm
+
+import (
+	storetypes "cosmossdk.io/store/types"
+)
+
+// Option configures the behavior of `ExecuteBlock`.
+type Option func(*options)
+
+type options struct {
+	listener   ChangeListener
+	changeSets func([]ChangeSet)
+	dryRun     func(*PendingBlock)
+	trace      func(*BlockTrace)
+	stats      func(SchedulerStats)
+	digest     func([]byte)
+
+	snapshotOptions SnapshotOptions
+	skippedWrites   func(int)
+	writeStats      func([]StoreWriteStats)
+
+	viewOptions ViewOptions
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{viewOptions: DefaultViewOptions()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// validate checks the options are supported by the stores.
+func (o *options) validate(stores map[storetypes.StoreKey]int) error {
+	if o.trace != nil {
+		return checkTraceStores(stores)
+	}
+	return nil
+}
+
+// WithChangeListener registers a listener to receive the change records of the block,
+// it's called in transaction order before the snapshot is written into the storage,
+// in dry-run mode it's deferred to `PendingBlock.Commit`.
+func WithChangeListener(listener ChangeListener) Option {
+	return func(o *options) {
+		o.listener = listener
+	}
+}
+
+// WithChangeSets registers a callback to receive the per-transaction change sets of the block,
+// it's called before the snapshot is written into the storage.
+func WithChangeSets(cb func([]ChangeSet)) Option {
+	return func(o *options) {
+		o.changeSets = cb
+	}
+}
+
+// WithDryRun skips writing the snapshot into the storage, the executed block is passed to the callback instead,
+// the caller can query the post-block state, and commit or discard it later.
+func WithDryRun(cb func(*PendingBlock)) Option {
+	return func(o *options) {
+		o.dryRun = cb
+	}
+}
+
+// WithTrace registers a callback to receive the trace of the block, see `NewBlockTrace`,
+// it's called before the snapshot is written into the storage.
+func WithTrace(cb func(*BlockTrace)) Option {
+	return func(o *options) {
+		o.trace = cb
+	}
+}
+
+// WithStats registers a callback to receive the scheduler metrics of the block,
+// it's called before the snapshot is written into the storage.
+func WithStats(cb func(SchedulerStats)) Option {
+	return func(o *options) {
+		o.stats = cb
+	}
+}
+
+// WithDigest registers a callback to receive the digest of the final snapshot, see `MVMemory.Digest`,
+// it's called before the snapshot is written into the storage.
+func WithDigest(cb func([]byte)) Option {
+	return func(o *options) {
+		o.digest = cb
+	}
+}
+
+// WithSkipNoopWrites compares the final values with the storage when committing the block, and skips the writes which
+// don't change it, see `SnapshotOptions.SkipNoop`, the optional callback receives the number of skipped writes.
+func WithSkipNoopWrites(cb func(skipped int)) Option {
+	return func(o *options) {
+		o.snapshotOptions.SkipNoop = true
+		o.skippedWrites = cb
+	}
+}
+
+// WithParallelWrites writes the stores concurrently when committing the block, the parent stores must be independent
+// and safe to write concurrently.
+func WithParallelWrites() Option {
+	return func(o *options) {
+		o.snapshotOptions.Parallel = true
+	}
+}
+
+// WithSortedWrites writes the stores sequentially in the order of store name when committing the block, so the writes
+// are deterministic for the callers tracing them, it takes precedence over `WithParallelWrites`.
+func WithSortedWrites() Option {
+	return func(o *options) {
+		o.snapshotOptions.Sorted = true
+	}
+}
+
+// WithWriteStats registers a callback to receive the write counts and durations of each store, sorted by name,
+// it's called after the snapshot is written into the storage.
+func WithWriteStats(cb func([]StoreWriteStats)) Option {
+	return func(o *options) {
+		o.writeStats = cb
+	}
+}
@@ -0,0 +1,74 @@
+package block_stm
+
+// PendingBlock holds the final multi-version state of an executed block which is not written into storage yet.
+// It's not thread-safe.
+type PendingBlock struct {
+	mvMemory *MVMemory
+	listener ChangeListener
+
+	// see `WithSkipNoopWrites`, `WithParallelWrites`, `WithSortedWrites` and `WithWriteStats`
+	snapshotOptions SnapshotOptions
+	skippedWrites   func(int)
+	writeStats      func([]StoreWriteStats)
+}
+
+// NewPendingBlock wraps the multi-version memory of an executed block, the write options, i.e. `WithSkipNoopWrites`,
+// `WithParallelWrites`, `WithSortedWrites` and `WithWriteStats`, apply when it's committed, the others are ignored.
+func NewPendingBlock(mvMemory *MVMemory, listener ChangeListener, opts ...Option) *PendingBlock {
+	o := newOptions(opts)
+	o.listener = listener
+	return newPendingBlock(mvMemory, o)
+}
+
+func newPendingBlock(mvMemory *MVMemory, o *options) *PendingBlock {
+	return &PendingBlock{
+		mvMemory:        mvMemory,
+		listener:        o.listener,
+		snapshotOptions: o.snapshotOptions,
+		skippedWrites:   o.skippedWrites,
+		writeStats:      o.writeStats,
+	}
+}
+
+// MVMemory returns the underlying multi-version memory, panics if the block is already committed or discarded.
+func (b *PendingBlock) MVMemory() *MVMemory {
+	if b.mvMemory == nil {
+		panic("pending block is already committed or discarded")
+	}
+	return b.mvMemory
+}
+
+// State returns a read-only view of the post-block state, it reads the final multi-version data and falls back to the
+// parent storage.
+//
+// To pipeline the execution, pass it as the parent storage of the next block executed in dry-run mode, the next block
+// must be committed after this one, and the parent storage must support concurrent reads while this one commits.
+func (b *PendingBlock) State() *ReadOnlyMultiView {
+	mv := b.MVMemory()
+	return mv.ViewAt(mv.BlockSize())
+}
+
+// Commit streams the changes to the listener if any, and writes the snapshot into the storage.
+func (b *PendingBlock) Commit(storage MultiStore) {
+	mv := b.MVMemory()
+	if b.listener != nil {
+		mv.StreamChanges(b.listener)
+	}
+	stats := mv.WriteSnapshotWithOptions(storage, b.snapshotOptions)
+	if b.skippedWrites != nil {
+		var skipped int
+		for _, s := range stats {
+			skipped += s.Skipped
+		}
+		b.skippedWrites(skipped)
+	}
+	if b.writeStats != nil {
+		b.writeStats(stats)
+	}
+	b.mvMemory = nil
+}
+
+// Discard drops the executed state without touching the storage.
+func (b *PendingBlock) Discard() {
+	b.mvMemory = nil
+}
@@ -0,0 +1,184 @@
+package block_stm
+
+import (
+	"context"
+	"testing"
+
+	storetypes "cosmossdk.io/store/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	blk := testBlock(100, 10)
+	storage := NewMultiMemDB(stores)
+
+	var pending *PendingBlock
+	require.NoError(t, ExecuteBlock(
+		context.Background(), blk.Size(), stores, storage, 10, blk.ExecuteTx,
+		WithDryRun(func(p *PendingBlock) {
+			pending = p
+		}),
+	))
+	require.NotNil(t, pending)
+
+	// storage is not touched
+	for store := range stores {
+		require.True(t, StoreEqual(NewMemDB(), storage.GetKVStore(store)))
+	}
+
+	crossCheck := NewMultiMemDB(stores)
+	runSequential(crossCheck, blk)
+
+	// query the post-block state
+	for store := range stores {
+		require.True(t, StoreEqual(crossCheck.GetKVStore(store), pending.State().GetKVStore(store)))
+	}
+
+	pending.Commit(storage)
+	for store := range stores {
+		require.True(t, StoreEqual(crossCheck.GetKVStore(store), storage.GetKVStore(store)))
+	}
+	require.Panics(t, func() { pending.Commit(storage) })
+}
+
+func TestDryRunDiscard(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	blk := testBlock(10, 3)
+	storage := NewMultiMemDB(stores)
+
+	var called bool
+	var pending *PendingBlock
+	require.NoError(t, ExecuteBlock(
+		context.Background(), blk.Size(), stores, storage, 3, blk.ExecuteTx,
+		WithDryRun(func(p *PendingBlock) {
+			pending = p
+		}),
+		WithChangeListener(func(TxnIndex, []*storetypes.StoreKVPair) {
+			called = true
+		}),
+	))
+
+	pending.Discard()
+	require.False(t, called)
+	require.Panics(t, func() { pending.State() })
+	for store := range stores {
+		require.True(t, StoreEqual(NewMemDB(), storage.GetKVStore(store)))
+	}
+}
+
+func TestPipelinedExecution(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	blk1 := testBlock(100, 10)
+	blk2 := iterateBlock(100, 10)
+	storage := NewMultiMemDB(stores)
+
+	var pending1, pending2 *PendingBlock
+	require.NoError(t, ExecuteBlock(
+		context.Background(), blk1.Size(), stores, storage, 10, blk1.ExecuteTx,
+		WithDryRun(func(p *PendingBlock) {
+			pending1 = p
+		}),
+	))
+
+	// execute the next block on top of the uncommitted one, while committing it concurrently
+	state := pending1.State()
+	committed := make(chan struct{})
+	go func() {
+		pending1.Commit(storage)
+		close(committed)
+	}()
+	require.NoError(t, ExecuteBlock(
+		context.Background(), blk2.Size(), stores, state, 10, blk2.ExecuteTx,
+		WithDryRun(func(p *PendingBlock) {
+			pending2 = p
+		}),
+	))
+	<-committed
+	pending2.Commit(storage)
+
+	crossCheck := NewMultiMemDB(stores)
+	runSequential(crossCheck, blk1)
+	runSequential(crossCheck, blk2)
+	for store := range stores {
+		require.True(t, StoreEqual(crossCheck.GetKVStore(store), storage.GetKVStore(store)))
+	}
+}
+
+func TestSkipNoopWrites(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	storage := NewMultiMemDB(stores)
+	storage.GetKVStore(StoreKeyAuth).Set(Key("a"), []byte("1"))
+
+	blk := NewMockBlock([]Tx{
+		func(store MultiStore) error {
+			auth := store.GetKVStore(StoreKeyAuth)
+			auth.Set(Key("a"), []byte("2"))
+			auth.Set(Key("b"), []byte("1"))
+			store.GetKVStore(StoreKeyBank).Delete(Key("absent"))
+			return nil
+		},
+		func(store MultiStore) error {
+			// revert "a" to the original value, and delete "b" again
+			auth := store.GetKVStore(StoreKeyAuth)
+			auth.Set(Key("a"), []byte("1"))
+			auth.Delete(Key("b"))
+			return nil
+		},
+	})
+
+	var pending *PendingBlock
+	skipped := -1
+	require.NoError(t, ExecuteBlock(
+		context.Background(), blk.Size(), stores, storage, 2, blk.ExecuteTx,
+		WithDryRun(func(p *PendingBlock) {
+			pending = p
+		}),
+		WithSkipNoopWrites(func(n int) {
+			skipped = n
+		}),
+	))
+	pending.Commit(storage)
+	require.Equal(t, 3, skipped)
+	require.Equal(t, []byte("1"), storage.GetKVStore(StoreKeyAuth).Get(Key("a")))
+	require.False(t, storage.GetKVStore(StoreKeyAuth).Has(Key("b")))
+}
+
+func TestNewPendingBlockOptions(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	storage := NewMultiMemDB(stores)
+	storage.GetKVStore(StoreKeyAuth).Set(Key("a"), []byte("1"))
+
+	blk := NewMockBlock([]Tx{
+		func(store MultiStore) error {
+			auth := store.GetKVStore(StoreKeyAuth)
+			auth.Set(Key("a"), []byte("1"))
+			auth.Set(Key("b"), []byte("1"))
+			return nil
+		},
+	})
+
+	var mv *MVMemory
+	require.NoError(t, ExecuteBlock(
+		context.Background(), blk.Size(), stores, storage, 1, blk.ExecuteTx,
+		WithDryRun(func(p *PendingBlock) {
+			mv = p.MVMemory()
+		}),
+	))
+
+	var (
+		skipped int
+		stats   []StoreWriteStats
+	)
+	NewPendingBlock(mv, nil,
+		WithSkipNoopWrites(func(n int) {
+			skipped = n
+		}),
+		WithWriteStats(func(s []StoreWriteStats) {
+			stats = s
+		}),
+	).Commit(storage)
+	require.Equal(t, 1, skipped)
+	require.Len(t, stats, len(stores))
+	require.Equal(t, []byte("1"), storage.GetKVStore(StoreKeyAuth).Get(Key("b")))
+}
@@ -0,0 +1,137 @@
+package block_stm
+
+import (
+	"fmt"
+	"io"
+
+	"cosmossdk.io/store/cachekv"
+	"cosmossdk.io/store/tracekv"
+	storetypes "cosmossdk.io/store/types"
+)
+
+var (
+	_ storetypes.KVStore    = (*GReadOnlyView[[]byte])(nil)
+	_ storetypes.ObjKVStore = (*GReadOnlyView[any])(nil)
+	_ MultiStore            = (*ReadOnlyMultiView)(nil)
+)
+
+// GReadOnlyView[V] is a read-only view of the state as seen by a particular transaction,
+// it don't track the reads, so it should only be used after the block is executed.
+type GReadOnlyView[V any] struct {
+	storage storetypes.GKVStore[V]
+	mvData  *GMVData[V]
+	txn     TxnIndex
+}
+
+func NewReadOnlyView(storage storetypes.Store, mvData MVStore, txn TxnIndex) storetypes.Store {
+	return mvData.NewReadOnlyView(storage, txn)
+}
+
+func NewGReadOnlyView[V any](storage storetypes.GKVStore[V], mvData *GMVData[V], txn TxnIndex) *GReadOnlyView[V] {
+	return &GReadOnlyView[V]{
+		storage: storage,
+		mvData:  mvData,
+		txn:     txn,
+	}
+}
+
+func (s *GReadOnlyView[V]) Get(key []byte) V {
+	value, version, estimate := s.mvData.Read(key, s.txn)
+	if estimate {
+		panic(fmt.Sprintf("read estimate of txn %d in read-only view", version.Index))
+	}
+	if !version.Valid() {
+		return s.storage.Get(key)
+	}
+	return value
+}
+
+func (s *GReadOnlyView[V]) Has(key []byte) bool {
+	return !s.mvData.isZero(s.Get(key))
+}
+
+func (s *GReadOnlyView[V]) Set(key []byte, value V) {
+	panic("can't write into read-only view")
+}
+
+func (s *GReadOnlyView[V]) Delete(key []byte) {
+	panic("can't write into read-only view")
+}
+
+func (s *GReadOnlyView[V]) Iterator(start, end []byte) storetypes.GIterator[V] {
+	return s.iterator(IteratorOptions{Start: start, End: end, Ascending: true})
+}
+
+func (s *GReadOnlyView[V]) ReverseIterator(start, end []byte) storetypes.GIterator[V] {
+	return s.iterator(IteratorOptions{Start: start, End: end, Ascending: false})
+}
+
+func (s *GReadOnlyView[V]) iterator(opts IteratorOptions) storetypes.GIterator[V] {
+	// `nil` wait function, so the reads are not recorded
+	mvIter := s.mvData.Iterator(opts, s.txn, nil)
+
+	var parentIter storetypes.GIterator[V]
+	if opts.Ascending {
+		parentIter = s.storage.Iterator(opts.Start, opts.End)
+	} else {
+		parentIter = s.storage.ReverseIterator(opts.Start, opts.End)
+	}
+
+	return NewCacheMergeIterator(parentIter, mvIter, opts.Ascending, nil, s.mvData.isZero)
+}
+
+// CacheWrap implements types.Store.
+func (s *GReadOnlyView[V]) CacheWrap() storetypes.CacheWrap {
+	return cachekv.NewGStore(s, s.mvData.isZero, s.mvData.valueLen)
+}
+
+// CacheWrapWithTrace implements types.Store.
+func (s *GReadOnlyView[V]) CacheWrapWithTrace(w io.Writer, tc storetypes.TraceContext) storetypes.CacheWrap {
+	if store, ok := any(s).(*GReadOnlyView[[]byte]); ok {
+		return cachekv.NewGStore(tracekv.NewStore(store, w, tc), store.mvData.isZero, store.mvData.valueLen)
+	}
+	return s.CacheWrap()
+}
+
+// GetStoreType implements types.Store.
+func (s *GReadOnlyView[V]) GetStoreType() storetypes.StoreType {
+	return s.storage.GetStoreType()
+}
+
+// ReadOnlyMultiView is a read-only view of all the stores as seen by a particular transaction.
+// The store views are initialized upfront, so it's thread-safe as long as the parent storage is, it can be used as
+// the parent storage of the next block to pipeline the execution before the current block is committed.
+type ReadOnlyMultiView struct {
+	views map[storetypes.StoreKey]storetypes.Store
+}
+
+func (mv *ReadOnlyMultiView) GetStore(name storetypes.StoreKey) storetypes.Store {
+	view, ok := mv.views[name]
+	if !ok {
+		panic(ErrUnknownStore{Store: name.Name()})
+	}
+	return view
+}
+
+func (mv *ReadOnlyMultiView) GetKVStore(name storetypes.StoreKey) storetypes.KVStore {
+	return mv.GetStore(name).(storetypes.KVStore)
+}
+
+func (mv *ReadOnlyMultiView) GetObjKVStore(name storetypes.StoreKey) storetypes.ObjKVStore {
+	return mv.GetStore(name).(storetypes.ObjKVStore)
+}
+
+// ViewAt returns a read-only view of the state right before the txn is executed,
+// `ViewAt(txn+1)` is the state right after it, and `ViewAt(BlockSize())` is the post-block state.
+// It should only be called after the block is executed.
+func (mv *MVMemory) ViewAt(txn TxnIndex) *ReadOnlyMultiView {
+	if txn < 0 || txn > mv.BlockSize() {
+		panic(fmt.Sprintf("txn index out of range: %d", txn))
+	}
+
+	views := make(map[storetypes.StoreKey]storetypes.Store, len(mv.stores))
+	for name, i := range mv.stores {
+		views[name] = NewReadOnlyView(mv.storage.GetStore(name), mv.GetMVStore(i), txn)
+	}
+	return &ReadOnlyMultiView{views: views}
+}
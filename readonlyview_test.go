@@ -0,0 +1,46 @@
+package block_stm
+
+import (
+	"context"
+	"testing"
+
+	storetypes "cosmossdk.io/store/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestViewAt(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	blk := iterateBlock(20, 5)
+	storage := NewMultiMemDB(stores)
+	storage.GetKVStore(StoreKeyBank).Set([]byte("balance"+accountName(0)), []byte("00000000"))
+
+	var pending *PendingBlock
+	require.NoError(t, ExecuteBlock(
+		context.Background(), blk.Size(), stores, storage, 5, blk.ExecuteTx,
+		WithDryRun(func(p *PendingBlock) {
+			pending = p
+		}),
+	))
+	mv := pending.MVMemory()
+
+	for i := 0; i <= blk.Size(); i++ {
+		crossCheck := NewMultiMemDB(stores)
+		crossCheck.GetKVStore(StoreKeyBank).Set([]byte("balance"+accountName(0)), []byte("00000000"))
+		runSequential(crossCheck, NewMockBlock(blk.Txs[:i]))
+
+		view := mv.ViewAt(TxnIndex(i))
+		for store := range stores {
+			require.True(t, StoreEqual(crossCheck.GetKVStore(store), view.GetKVStore(store)), "txn %d", i)
+		}
+	}
+
+	view := mv.ViewAt(0).GetKVStore(StoreKeyBank)
+	require.Equal(t, []byte("00000000"), view.Get([]byte("balance"+accountName(0))))
+	require.Panics(t, func() { view.Set(Key("a"), []byte("1")) })
+	require.Panics(t, func() { view.Delete(Key("a")) })
+	require.Panics(t, func() { mv.ViewAt(TxnIndex(blk.Size() + 1)) })
+
+	unknown := storetypes.NewKVStoreKey("mint")
+	require.Equal(t, ErrUnknownStore{Store: "mint"}, recoverPanic(func() { mv.ViewAt(0).GetKVStore(unknown) }))
+	require.Equal(t, ErrUnknownStore{Store: "mint"}, recoverPanic(func() { pending.State().GetStore(unknown) }))
+}
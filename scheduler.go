@@ -51,6 +51,19 @@ type Scheduler struct {
 	// metrics
 	executedTxns  atomic.Int64
 	validatedTxns atomic.Int64
+	abortedTxns   atomic.Int64
+	suspendedTxns atomic.Int64
+}
+
+// SchedulerStats is a snapshot of the scheduler metrics.
+type SchedulerStats struct {
+	// Executed and Validated count the execution and validation tasks, including the re-executions.
+	Executed  int64
+	Validated int64
+	// Aborted counts the incarnations aborted by failed validations.
+	Aborted int64
+	// Suspended counts the executions suspended on a dependency, i.e. read an estimate.
+	Suspended int64
 }
 
 func NewScheduler(block_size int) *Scheduler {
@@ -61,6 +74,22 @@ func NewScheduler(block_size int) *Scheduler {
 	}
 }
 
+// SetExecuted marks a transaction as executed and resumes its dependents without scheduling validation,
+// used when the execution is driven outside of the task loop, e.g. the results are reused from a previous execution,
+// the transaction needs to be validated separately.
+func (s *Scheduler) SetExecuted(txn TxnIndex) {
+	s.txn_status[txn].SetExecuted()
+	s.ResumeDependencies(s.txn_dependency[txn].Swap(nil))
+}
+
+// TxnIncarnation returns the current incarnation of the transaction.
+func (s *Scheduler) TxnIncarnation(txn TxnIndex) Incarnation {
+	entry := &s.txn_status[txn]
+	entry.Lock()
+	defer entry.Unlock()
+	return entry.incarnation
+}
+
 func (s *Scheduler) Done() bool {
 	return s.done_marker.Load()
 }
@@ -159,6 +188,7 @@ func (s *Scheduler) WaitForDependency(txn TxnIndex, blocking_txn TxnIndex) *Cond
 	}
 
 	s.txn_status[txn].Suspend(cond)
+	s.suspendedTxns.Add(1)
 	entry.dependents = append(entry.dependents, txn)
 	entry.Unlock()
 
@@ -196,6 +226,7 @@ func (s *Scheduler) TryValidationAbort(version TxnVersion) bool {
 // Invariant `num_active_tasks`: decreased if an invalid task is returned.
 func (s *Scheduler) FinishValidation(txn TxnIndex, aborted bool) (TxnVersion, TaskKind) {
 	if aborted {
+		s.abortedTxns.Add(1)
 		s.txn_status[txn].SetReadyStatus()
 		s.DecreaseValidationIdx(txn + 1)
 		if s.execution_idx.Load() > uint64(txn) {
@@ -208,6 +239,25 @@ func (s *Scheduler) FinishValidation(txn TxnIndex, aborted bool) (TxnVersion, Ta
 }
 
 func (s *Scheduler) Stats() string {
-	return fmt.Sprintf("executed: %d, validated: %d",
-		s.executedTxns.Load(), s.validatedTxns.Load())
+	return fmt.Sprintf("executed: %d, validated: %d, aborted: %d, suspended: %d",
+		s.executedTxns.Load(), s.validatedTxns.Load(), s.abortedTxns.Load(), s.suspendedTxns.Load())
+}
+
+// Metrics returns a snapshot of the scheduler metrics, it's reported by `WithStats` when the block finishes.
+func (s *Scheduler) Metrics() SchedulerStats {
+	return SchedulerStats{
+		Executed:  s.executedTxns.Load(),
+		Validated: s.validatedTxns.Load(),
+		Aborted:   s.abortedTxns.Load(),
+		Suspended: s.suspendedTxns.Load(),
+	}
+}
+
+// addMetrics adds the metrics of a previous scheduler of the same block, e.g. the first phase of
+// `ExecuteBlockValidateOnly`.
+func (s *Scheduler) addMetrics(stats SchedulerStats) {
+	s.executedTxns.Add(stats.Executed)
+	s.validatedTxns.Add(stats.Validated)
+	s.abortedTxns.Add(stats.Aborted)
+	s.suspendedTxns.Add(stats.Suspended)
 }
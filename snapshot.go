@@ -0,0 +1,88 @@
+package block_stm
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	storetypes "cosmossdk.io/store/types"
+)
+
+// SnapshotOptions configures how the final snapshot is written into the storage.
+type SnapshotOptions struct {
+	// SkipNoop skips the writes which don't change the storage, it reads every written key from the storage,
+	// see `GMVData.SnapshotToStore`.
+	SkipNoop bool
+	// Parallel writes the stores concurrently, the parent stores must be independent and safe to write concurrently.
+	Parallel bool
+	// Sorted writes the stores sequentially in the order of store name, for the callers tracing the writes,
+	// it takes precedence over `Parallel`.
+	Sorted bool
+}
+
+// StoreWriteStats reports the writes of a store when writing the snapshot.
+type StoreWriteStats struct {
+	Store    string
+	Written  int
+	Skipped  int
+	Duration time.Duration
+}
+
+// WriteSnapshot writes the final snapshot into the storage, the stores are written sequentially in no particular order.
+func (mv *MVMemory) WriteSnapshot(storage MultiStore) {
+	mv.WriteSnapshotWithOptions(storage, SnapshotOptions{})
+}
+
+// WriteSnapshotSkipNoop writes the snapshot like `WriteSnapshot`, but skips the writes which don't change the storage,
+// it reads every written key from the storage, returns the number of skipped writes.
+func (mv *MVMemory) WriteSnapshotSkipNoop(storage MultiStore) (skipped int) {
+	for _, s := range mv.WriteSnapshotWithOptions(storage, SnapshotOptions{SkipNoop: true}) {
+		skipped += s.Skipped
+	}
+	return
+}
+
+// WriteSnapshotWithOptions writes the final snapshot into the storage, returns the stats of each store, sorted by name.
+func (mv *MVMemory) WriteSnapshotWithOptions(storage MultiStore, opts SnapshotOptions) []StoreWriteStats {
+	// indexed by store
+	stats := make([]StoreWriteStats, len(mv.data))
+	write := func(name storetypes.StoreKey, store storetypes.Store) {
+		i := mv.stores[name]
+		start := time.Now()
+		written, skipped := mv.data[i].SnapshotToStore(store, opts.SkipNoop)
+		stats[i] = StoreWriteStats{
+			Store:    name.Name(),
+			Written:  written,
+			Skipped:  skipped,
+			Duration: time.Since(start),
+		}
+	}
+
+	switch {
+	case opts.Sorted:
+		for _, name := range mv.sortedStores() {
+			write(name, storage.GetStore(name))
+		}
+	case opts.Parallel:
+		// the parent storage is not necessarily thread-safe, only the stores are written concurrently
+		var wg sync.WaitGroup
+		wg.Add(len(mv.stores))
+		for name := range mv.stores {
+			store := storage.GetStore(name)
+			go func(name storetypes.StoreKey) {
+				defer wg.Done()
+				write(name, store)
+			}(name)
+		}
+		wg.Wait()
+	default:
+		for name := range mv.stores {
+			write(name, storage.GetStore(name))
+		}
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Store < stats[j].Store
+	})
+	return stats
+}
@@ -0,0 +1,93 @@
+package block_stm
+
+import (
+	"context"
+	"testing"
+
+	storetypes "cosmossdk.io/store/types"
+	"github.com/test-go/testify/require"
+)
+
+// recordingMultiStore records the order of the stores accessed.
+type recordingMultiStore struct {
+	*MultiMemDB
+	names []string
+}
+
+func (s *recordingMultiStore) GetStore(key storetypes.StoreKey) storetypes.Store {
+	s.names = append(s.names, key.Name())
+	return s.MultiMemDB.GetStore(key)
+}
+
+func TestWriteSnapshotWithOptions(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{
+		StoreKeyAuth:                        0,
+		StoreKeyBank:                        1,
+		storetypes.NewKVStoreKey("mint"):    2,
+		storetypes.NewKVStoreKey("staking"): 3,
+	}
+	blk := testBlock(100, 20)
+	storage := NewMultiMemDB(stores)
+	var pending *PendingBlock
+	require.NoError(t, ExecuteBlock(
+		context.Background(), blk.Size(), stores, storage, 4, blk.ExecuteTx,
+		WithDryRun(func(p *PendingBlock) {
+			pending = p
+		}),
+	))
+	mv := pending.MVMemory()
+
+	crossCheck := NewMultiMemDB(stores)
+	runSequential(crossCheck, blk)
+
+	for _, opts := range []SnapshotOptions{
+		{},
+		{Sorted: true},
+		{Parallel: true},
+		{Parallel: true, SkipNoop: true},
+	} {
+		storage := &recordingMultiStore{MultiMemDB: NewMultiMemDB(stores)}
+		stats := mv.WriteSnapshotWithOptions(storage, opts)
+		for store := range stores {
+			require.True(t, StoreEqual(crossCheck.GetKVStore(store), storage.GetKVStore(store)))
+		}
+
+		require.Len(t, stats, 4)
+		require.Equal(t, "acc", stats[0].Store)
+		require.Equal(t, "bank", stats[1].Store)
+		require.Equal(t, "mint", stats[2].Store)
+		require.Equal(t, "staking", stats[3].Store)
+		require.NotZero(t, stats[0].Written)
+		require.NotZero(t, stats[1].Written)
+		require.Zero(t, stats[2].Written)
+		require.Zero(t, stats[3].Written)
+
+		if opts.Sorted {
+			require.Equal(t, []string{"acc", "bank", "mint", "staking"}, storage.names[:4])
+		}
+	}
+}
+
+func TestWriteStats(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	blk := testBlock(100, 20)
+	storage := NewMultiMemDB(stores)
+
+	var stats []StoreWriteStats
+	require.NoError(t, ExecuteBlock(
+		context.Background(), blk.Size(), stores, storage, 4, blk.ExecuteTx,
+		WithParallelWrites(),
+		WithWriteStats(func(s []StoreWriteStats) {
+			stats = s
+		}),
+	))
+
+	crossCheck := NewMultiMemDB(stores)
+	runSequential(crossCheck, blk)
+	require.Len(t, stats, 2)
+	for i, store := range []storetypes.StoreKey{StoreKeyAuth, StoreKeyBank} {
+		require.True(t, StoreEqual(crossCheck.GetKVStore(store), storage.GetKVStore(store)))
+		require.Equal(t, store.Name(), stats[i].Store)
+		require.Equal(t, storage.GetStore(store).(*MemDB).Len(), stats[i].Written)
+	}
+}
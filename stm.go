@@ -17,10 +17,11 @@ func ExecuteBlock(
 	storage MultiStore,
 	executors int,
 	txExecutor TxExecutor,
+	opts ...Option,
 ) error {
 	return ExecuteBlockWithEstimates(
 		ctx, blockSize, stores, storage, executors,
-		nil, txExecutor,
+		nil, txExecutor, opts...,
 	)
 }
 
@@ -32,17 +33,43 @@ func ExecuteBlockWithEstimates(
 	executors int,
 	estimates []MultiLocations, // txn -> multi-locations
 	txExecutor TxExecutor,
+	opts ...Option,
 ) error {
 	if executors < 0 {
 		return fmt.Errorf("invalid number of executors: %d", executors)
 	}
-	if executors == 0 {
-		executors = maxParallelism()
+	if err := ValidateStores(stores, storage); err != nil {
+		return err
+	}
+
+	o := newOptions(opts)
+	if err := o.validate(stores); err != nil {
+		return err
 	}
 
 	// Create a new scheduler
 	scheduler := NewScheduler(blockSize)
 	mvMemory := NewMVMemoryWithEstimates(blockSize, stores, storage, scheduler, estimates)
+	mvMemory.SetViewOptions(o.viewOptions)
+	return runBlock(ctx, scheduler, mvMemory, storage, executors, txExecutor, o)
+}
+
+// runBlock runs the executors until the scheduler is done, then commits the block or hands it over in dry-run mode.
+func runBlock(
+	ctx context.Context,
+	scheduler *Scheduler,
+	mvMemory *MVMemory,
+	storage MultiStore,
+	executors int,
+	txExecutor TxExecutor,
+	o *options,
+) error {
+	if executors == 0 {
+		executors = maxParallelism()
+	}
+	if o.trace != nil {
+		mvMemory.EnableAbortTrace()
+	}
 
 	var wg sync.WaitGroup
 	wg.Add(executors)
@@ -64,8 +91,37 @@ func ExecuteBlockWithEstimates(
 		return errors.New("scheduler did not complete")
 	}
 
+	return finishBlock(mvMemory, storage, o)
+}
+
+// finishBlock commits the executed block into storage, or hands it over in dry-run mode, unless a transaction accessed an
+// unknown store.
+func finishBlock(mvMemory *MVMemory, storage MultiStore, o *options) error {
+	if err := mvMemory.Err(); err != nil {
+		return err
+	}
+	if o.changeSets != nil {
+		o.changeSets(mvMemory.ChangeSets())
+	}
+	if o.trace != nil {
+		o.trace(NewBlockTrace(mvMemory))
+	}
+	if o.stats != nil && mvMemory.scheduler != nil {
+		o.stats(mvMemory.scheduler.Metrics())
+	}
+	if o.digest != nil {
+		o.digest(mvMemory.Digest())
+	}
+
+	pending := newPendingBlock(mvMemory, o)
+	if o.dryRun != nil {
+		// leave the commit decision to the caller
+		o.dryRun(pending)
+		return nil
+	}
+
 	// Write the snapshot into the storage
-	mvMemory.WriteSnapshot(storage)
+	pending.Commit(storage)
 	return nil
 }
 
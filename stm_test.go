@@ -172,3 +172,23 @@ func StoreEqual(a, b storetypes.KVStore) bool {
 		iter2.Next()
 	}
 }
+
+func TestSTMStats(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	blk := worstCaseBlock(100)
+
+	var stats SchedulerStats
+	require.NoError(t, ExecuteBlock(
+		context.Background(), blk.Size(), stores, NewMultiMemDB(stores), 1, blk.ExecuteTx,
+		WithStats(func(s SchedulerStats) { stats = s }),
+	))
+	// a single executor never conflicts
+	require.Equal(t, SchedulerStats{Executed: 100, Validated: 100}, stats)
+
+	require.NoError(t, ExecuteBlock(
+		context.Background(), blk.Size(), stores, NewMultiMemDB(stores), 8, blk.ExecuteTx,
+		WithStats(func(s SchedulerStats) { stats = s }),
+	))
+	require.Equal(t, int64(100)+stats.Aborted, stats.Executed)
+	require.True(t, stats.Validated >= stats.Executed)
+}
@@ -0,0 +1,444 @@
+package block_stm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	storetypes "cosmossdk.io/store/types"
+)
+
+// traceMagic is the header of the trace file, followed by `CodecVersion`.
+var traceMagic = []byte("STMTRACE")
+
+// BlockTrace is the recorded accesses of an executed block, it can be replayed without the application.
+type BlockTrace struct {
+	// Stores are indexed by the store index.
+	Stores []StoreTrace
+	Txs    []TxnTrace
+}
+
+// StoreTrace records a `[]byte` store, or an object store if `Object` is set, the other value types can't be traced.
+type StoreTrace struct {
+	Name   string
+	Object bool
+	// PreState is the parent state observed by the block, sorted by key, only recorded for kv stores.
+	PreState []KVPair
+}
+
+type TxnTrace struct {
+	// Incarnations are the outcomes of the incarnations in order, all but the last one are aborted by failed
+	// validations, the last one is committed.
+	Incarnations []IncarnationTrace
+	ReadSet      MultiReadSet
+	// Writes is the final write set, store index -> pairs sorted by key, `nil` value means deleted.
+	// The object store values are not recorded, they are replayed as empty byte slices.
+	Writes map[int][]KVPair
+}
+
+// IncarnationTrace is the outcome of an incarnation, `Conflict` is the first invalid read found by the validation
+// which aborted it, nil if committed.
+type IncarnationTrace struct {
+	Conflict *ReadConflict
+}
+
+func (it IncarnationTrace) Aborted() bool {
+	return it.Conflict != nil
+}
+
+// ReExecutions returns the number of aborted incarnations.
+func (tx *TxnTrace) ReExecutions() int {
+	return max(len(tx.Incarnations)-1, 0)
+}
+
+// checkTraceStores returns an error if any store can't be traced, see `StoreTrace`.
+func checkTraceStores(stores map[storetypes.StoreKey]int) error {
+	for key := range stores {
+		if _, ok := key.(*storetypes.ObjectStoreKey); !ok && !isBytesStore(key) {
+			return fmt.Errorf("can't trace store %s of custom value type", key.Name())
+		}
+	}
+	return nil
+}
+
+// NewBlockTrace records the trace of an executed block, it must be called before the block is committed, because the
+// pre-state is read from the parent storage, the aborted incarnations are only recorded if `EnableAbortTrace` is
+// called before the execution, it panics if any store can't be traced.
+func NewBlockTrace(mv *MVMemory) *BlockTrace {
+	if err := checkTraceStores(mv.stores); err != nil {
+		panic(err)
+	}
+
+	trace := &BlockTrace{
+		Stores: make([]StoreTrace, len(mv.stores)),
+		Txs:    make([]TxnTrace, mv.BlockSize()),
+	}
+	for name, i := range mv.stores {
+		_, object := name.(*storetypes.ObjectStoreKey)
+		trace.Stores[i] = StoreTrace{Name: name.Name(), Object: object}
+	}
+
+	// store index -> keys read from storage
+	preKeys := make(map[int]map[string]struct{}, len(mv.stores))
+	for txn := range trace.Txs {
+		tx := &trace.Txs[txn]
+		var incarnation Incarnation
+		if mv.scheduler != nil {
+			incarnation = mv.scheduler.TxnIncarnation(TxnIndex(txn))
+		}
+		tx.Incarnations = make([]IncarnationTrace, incarnation+1)
+		for i := Incarnation(0); i < incarnation; i++ {
+			conflict := mv.abortConflict(TxnVersion{TxnIndex(txn), i})
+			if conflict == nil {
+				// not recorded, the reason is unknown
+				conflict = &ReadConflict{}
+			}
+			tx.Incarnations[i].Conflict = conflict
+		}
+		if rs := mv.lastReadSet[txn].Load(); rs != nil {
+			tx.ReadSet = *rs
+		}
+
+		for store, rs := range tx.ReadSet {
+			if trace.Stores[store].Object {
+				continue
+			}
+			if preKeys[store] == nil {
+				preKeys[store] = make(map[string]struct{})
+			}
+			collectStorageReads(rs, preKeys[store])
+		}
+
+		for store, locations := range mv.readLastWrittenLocations(TxnIndex(txn)) {
+			pairs := mv.data[store].TxnWrites(TxnIndex(txn), locations)
+			if len(pairs) == 0 {
+				continue
+			}
+			if tx.Writes == nil {
+				tx.Writes = make(map[int][]KVPair)
+			}
+
+			writes := make([]KVPair, len(pairs))
+			for i, pair := range pairs {
+				writes[i].Key = pair.Key
+				switch value := pair.Value.(type) {
+				case nil:
+				case []byte:
+					writes[i].Value = value
+				default:
+					writes[i].Value = []byte{}
+				}
+			}
+			tx.Writes[store] = writes
+		}
+	}
+
+	for name, i := range mv.stores {
+		keys := preKeys[i]
+		if len(keys) == 0 {
+			continue
+		}
+
+		kv := mv.storage.GetKVStore(name)
+		var pairs []KVPair
+		for key := range keys {
+			if value := kv.Get([]byte(key)); value != nil {
+				pairs = append(pairs, KVPair{Key(key), value})
+			}
+		}
+		sort.Slice(pairs, func(i, j int) bool {
+			return bytes.Compare(pairs[i].Key, pairs[j].Key) < 0
+		})
+		trace.Stores[i].PreState = pairs
+	}
+
+	return trace
+}
+
+func collectStorageReads(rs *ReadSet, keys map[string]struct{}) {
+	collect := func(reads []ReadDescriptor) {
+		for _, desc := range reads {
+			if !desc.Version.Valid() {
+				keys[string(desc.Key)] = struct{}{}
+			}
+		}
+	}
+	collect(rs.Reads)
+	for _, desc := range rs.Iterators {
+		collect(desc.Reads)
+	}
+}
+
+// WriteBlockTrace writes the trace in the binary format, see `BinaryCodec`.
+func WriteBlockTrace(w io.Writer, trace *BlockTrace) error {
+	buf := append(append([]byte{}, traceMagic...), CodecVersion)
+
+	buf = binary.AppendUvarint(buf, uint64(len(trace.Stores)))
+	for _, store := range trace.Stores {
+		buf = appendNullableBytes(buf, []byte(store.Name))
+		if store.Object {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+		buf = appendKVPairs(buf, store.PreState)
+	}
+
+	buf = binary.AppendUvarint(buf, uint64(len(trace.Txs)))
+	for _, tx := range trace.Txs {
+		buf = binary.AppendUvarint(buf, uint64(len(tx.Incarnations)))
+		for _, it := range tx.Incarnations {
+			if it.Conflict == nil {
+				buf = append(buf, 0)
+				continue
+			}
+			buf = append(buf, 1, byte(it.Conflict.Reason))
+			buf = binary.AppendUvarint(buf, uint64(it.Conflict.Store))
+			buf = appendNullableBytes(buf, it.Conflict.Key)
+		}
+		buf = appendMultiReadSet(buf, tx.ReadSet)
+		stores := sortedStoreIndexes(tx.Writes)
+		buf = binary.AppendUvarint(buf, uint64(len(stores)))
+		for _, store := range stores {
+			buf = binary.AppendUvarint(buf, uint64(store))
+			buf = appendKVPairs(buf, tx.Writes[store])
+		}
+	}
+
+	_, err := w.Write(buf)
+	return err
+}
+
+// ReadBlockTrace reads the trace written by `WriteBlockTrace`.
+func ReadBlockTrace(r io.Reader) (*BlockTrace, error) {
+	bz, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(bz, traceMagic) {
+		return nil, errors.New("not a block trace")
+	}
+
+	d, err := newBinaryDecoder(bz[len(traceMagic):])
+	if err != nil {
+		return nil, err
+	}
+
+	trace := &BlockTrace{}
+	trace.Stores = make([]StoreTrace, d.count())
+	for i := range trace.Stores {
+		trace.Stores[i] = StoreTrace{
+			Name:     string(d.bytes()),
+			Object:   d.byte() != 0,
+			PreState: d.kvPairs(),
+		}
+	}
+
+	trace.Txs = make([]TxnTrace, d.count())
+	for i := 0; i < len(trace.Txs) && d.err == nil; i++ {
+		tx := &trace.Txs[i]
+		tx.Incarnations = make([]IncarnationTrace, d.count())
+		for j := range tx.Incarnations {
+			if d.byte() == 0 {
+				continue
+			}
+			tx.Incarnations[j].Conflict = &ReadConflict{
+				Reason: AbortReason(d.byte()),
+				Store:  d.traceStore(len(trace.Stores)),
+				Key:    d.bytes(),
+			}
+		}
+		tx.ReadSet = d.multiReadSet()
+		if n := d.count(); n > 0 {
+			tx.Writes = make(map[int][]KVPair, n)
+			for j := 0; j < n && d.err == nil; j++ {
+				store := storeIndex(d, tx.Writes)
+				tx.Writes[store] = d.kvPairs()
+			}
+		}
+		if d.err == nil {
+			d.err = checkTxnTrace(TxnIndex(i), tx, len(trace.Stores))
+		}
+	}
+
+	return trace, d.finish()
+}
+
+// traceStore reads a store index which must be in range.
+func (d *binaryDecoder) traceStore(stores int) int {
+	v := d.uvarint()
+	if d.err == nil && v >= uint64(stores) {
+		d.err = fmt.Errorf("trace: store index %d is out of range [0, %d)", v, stores)
+	}
+	return int(v)
+}
+
+// checkTxnTrace checks the decoded transaction refers to the existing stores, only reads the lower transactions, and
+// only the last incarnation is committed.
+func checkTxnTrace(txn TxnIndex, tx *TxnTrace, stores int) error {
+	if len(tx.Incarnations) == 0 {
+		return fmt.Errorf("trace: txn %d has no incarnation", txn)
+	}
+	for i, it := range tx.Incarnations {
+		if it.Aborted() == (i == len(tx.Incarnations)-1) {
+			return fmt.Errorf("trace: txn %d: only the last incarnation is committed", txn)
+		}
+	}
+
+	for store, rs := range tx.ReadSet {
+		if store >= stores {
+			return fmt.Errorf("trace: txn %d: store index %d is out of range [0, %d)", txn, store, stores)
+		}
+		check := func(reads []ReadDescriptor) error {
+			for _, desc := range reads {
+				if desc.Version.Valid() && desc.Version.Index >= txn {
+					return fmt.Errorf("trace: txn %d reads the version of txn %d", txn, desc.Version.Index)
+				}
+			}
+			return nil
+		}
+		if err := check(rs.Reads); err != nil {
+			return err
+		}
+		for _, desc := range rs.Iterators {
+			if err := check(desc.Reads); err != nil {
+				return err
+			}
+		}
+	}
+	for store := range tx.Writes {
+		if store >= stores {
+			return fmt.Errorf("trace: txn %d: store index %d is out of range [0, %d)", txn, store, stores)
+		}
+	}
+	return nil
+}
+
+func appendKVPairs(buf []byte, pairs []KVPair) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(pairs)))
+	for _, pair := range pairs {
+		buf = appendNullableBytes(buf, pair.Key)
+		buf = appendNullableBytes(buf, pair.Value)
+	}
+	return buf
+}
+
+func (d *binaryDecoder) kvPairs() []KVPair {
+	n := d.count()
+	if n == 0 {
+		return nil
+	}
+	pairs := make([]KVPair, n)
+	for i := range pairs {
+		pairs[i].Key = d.bytes()
+		pairs[i].Value = d.bytes()
+	}
+	return pairs
+}
+
+// Replay executes the recorded reads and writes of a block trace, to benchmark the engine without the application.
+type Replay struct {
+	trace *BlockTrace
+	keys  []storetypes.StoreKey
+}
+
+func NewReplay(trace *BlockTrace) *Replay {
+	keys := make([]storetypes.StoreKey, len(trace.Stores))
+	for i, store := range trace.Stores {
+		if store.Object {
+			keys[i] = storetypes.NewObjectStoreKey(store.Name)
+		} else {
+			keys[i] = storetypes.NewKVStoreKey(store.Name)
+		}
+	}
+	return &Replay{trace: trace, keys: keys}
+}
+
+func (r *Replay) Size() int {
+	return len(r.trace.Txs)
+}
+
+// Stores returns the stores argument of `ExecuteBlock`.
+func (r *Replay) Stores() map[storetypes.StoreKey]int {
+	stores := make(map[storetypes.StoreKey]int, len(r.keys))
+	for i, key := range r.keys {
+		stores[key] = i
+	}
+	return stores
+}
+
+// StoreKey returns the key of the store by index.
+func (r *Replay) StoreKey(i int) storetypes.StoreKey {
+	return r.keys[i]
+}
+
+// NewStorage returns a parent storage initialized with the recorded pre-state.
+func (r *Replay) NewStorage() *MultiMemDB {
+	storage := NewMultiMemDB(r.Stores())
+	for i, store := range r.trace.Stores {
+		if store.Object {
+			continue
+		}
+		kv := storage.GetKVStore(r.keys[i])
+		for _, pair := range store.PreState {
+			kv.Set(pair.Key, pair.Value)
+		}
+	}
+	return storage
+}
+
+// ExecuteTx is a `TxExecutor` which performs the recorded reads of the transaction, then the recorded writes.
+func (r *Replay) ExecuteTx(txn TxnIndex, ms MultiStore) {
+	tx := &r.trace.Txs[txn]
+	for _, i := range sortedStoreIndexes(tx.ReadSet) {
+		if r.trace.Stores[i].Object {
+			replayReads(ms.GetObjKVStore(r.keys[i]), tx.ReadSet[i])
+		} else {
+			replayReads(ms.GetKVStore(r.keys[i]), tx.ReadSet[i])
+		}
+	}
+
+	for _, i := range sortedStoreIndexes(tx.Writes) {
+		if r.trace.Stores[i].Object {
+			replayWrites(ms.GetObjKVStore(r.keys[i]), tx.Writes[i], func(v []byte) any { return v })
+		} else {
+			replayWrites(ms.GetKVStore(r.keys[i]), tx.Writes[i], func(v []byte) []byte { return v })
+		}
+	}
+}
+
+func replayReads[V any](store storetypes.GKVStore[V], rs *ReadSet) {
+	for _, desc := range rs.Reads {
+		store.Get(desc.Key)
+	}
+
+	for _, desc := range rs.Iterators {
+		var it storetypes.GIterator[V]
+		if desc.Ascending {
+			it = store.Iterator(desc.Start, desc.End)
+		} else {
+			it = store.ReverseIterator(desc.Start, desc.End)
+		}
+		for ; it.Valid(); it.Next() {
+			// the original iteration stops at the stop key
+			if desc.Stop != nil && !BytesBeyond(desc.Stop, it.Key(), desc.Ascending) {
+				break
+			}
+			it.Value()
+		}
+		it.Close()
+	}
+}
+
+func replayWrites[V any](store storetypes.GKVStore[V], pairs []KVPair, convert func([]byte) V) {
+	for _, pair := range pairs {
+		if pair.Value == nil {
+			store.Delete(pair.Key)
+		} else {
+			store.Set(pair.Key, convert(pair.Value))
+		}
+	}
+}
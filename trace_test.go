@@ -0,0 +1,168 @@
+package block_stm
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	storetypes "cosmossdk.io/store/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestBlockTraceReplay(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	blk := iterateBlock(100, 10)
+	storage := NewMultiMemDB(stores)
+	for i := int64(0); i < 10; i++ {
+		storage.GetKVStore(StoreKeyBank).Set([]byte("balance"+accountName(i)), []byte("00000000"))
+	}
+
+	var trace *BlockTrace
+	require.NoError(t, ExecuteBlock(
+		context.Background(), blk.Size(), stores, storage, 10, blk.ExecuteTx,
+		WithTrace(func(tr *BlockTrace) {
+			trace = tr
+		}),
+	))
+	require.Len(t, trace.Txs, blk.Size())
+	require.Equal(t, "acc", trace.Stores[0].Name)
+	require.Len(t, trace.Stores[1].PreState, 10)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteBlockTrace(&buf, trace))
+	bz := buf.Bytes()
+	decoded, err := ReadBlockTrace(bytes.NewReader(bz))
+	require.NoError(t, err)
+	require.Equal(t, trace.Stores, decoded.Stores)
+
+	// the encoding is deterministic
+	var buf2 bytes.Buffer
+	require.NoError(t, WriteBlockTrace(&buf2, decoded))
+	require.Equal(t, bz, buf2.Bytes())
+
+	replay := NewReplay(decoded)
+	replayStorage := replay.NewStorage()
+	require.NoError(t, ExecuteBlock(
+		context.Background(), replay.Size(), replay.Stores(), replayStorage, 10, replay.ExecuteTx,
+	))
+	for key, i := range stores {
+		require.True(t, StoreEqual(storage.GetKVStore(key), replayStorage.GetKVStore(replay.StoreKey(i))))
+	}
+
+	_, err = ReadBlockTrace(bytes.NewReader([]byte("garbage")))
+	require.Error(t, err)
+}
+
+func TestBlockTraceIncarnations(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	blk := testBlock(100, 10)
+
+	var (
+		trace *BlockTrace
+		stats SchedulerStats
+	)
+	require.NoError(t, ExecuteBlock(
+		context.Background(), blk.Size(), stores, NewMultiMemDB(stores), 10, blk.ExecuteTx,
+		WithTrace(func(tr *BlockTrace) {
+			trace = tr
+		}),
+		WithStats(func(s SchedulerStats) {
+			stats = s
+		}),
+	))
+
+	var aborted int
+	for _, tx := range trace.Txs {
+		last := len(tx.Incarnations) - 1
+		require.False(t, tx.Incarnations[last].Aborted())
+		for _, it := range tx.Incarnations[:last] {
+			require.True(t, it.Aborted())
+			require.NotZero(t, it.Conflict.Reason)
+			require.Contains(t, []int{0, 1}, it.Conflict.Store)
+		}
+		aborted += tx.ReExecutions()
+	}
+	require.Equal(t, int(stats.Aborted), aborted)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteBlockTrace(&buf, trace))
+	decoded, err := ReadBlockTrace(&buf)
+	require.NoError(t, err)
+	for txn, tx := range trace.Txs {
+		require.Equal(t, tx.Incarnations, decoded.Txs[txn].Incarnations)
+	}
+}
+
+func TestReadBlockTraceInvalid(t *testing.T) {
+	committed := []IncarnationTrace{{}}
+	for _, tc := range []struct {
+		name string
+		txs  []TxnTrace
+		err  string
+	}{
+		{
+			"write store out of range",
+			[]TxnTrace{{Incarnations: committed, Writes: map[int][]KVPair{1: {{Key("a"), []byte("1")}}}}},
+			"trace: txn 0: store index 1 is out of range [0, 1)",
+		},
+		{
+			"read store out of range",
+			[]TxnTrace{{Incarnations: committed, ReadSet: MultiReadSet{2: {}}}},
+			"trace: txn 0: store index 2 is out of range [0, 1)",
+		},
+		{
+			"conflict store out of range",
+			[]TxnTrace{{Incarnations: []IncarnationTrace{{Conflict: &ReadConflict{Reason: AbortReadChanged, Store: 1}}, {}}}},
+			"trace: store index 1 is out of range [0, 1)",
+		},
+		{
+			"read the same txn",
+			[]TxnTrace{{Incarnations: committed, ReadSet: MultiReadSet{0: {
+				Reads: []ReadDescriptor{{Key("a"), TxnVersion{Index: 0}}},
+			}}}},
+			"trace: txn 0 reads the version of txn 0",
+		},
+		{
+			"iterate a later txn",
+			[]TxnTrace{
+				{Incarnations: committed, ReadSet: MultiReadSet{0: {
+					Iterators: []IteratorDescriptor{{Reads: []ReadDescriptor{{Key("a"), TxnVersion{Index: 1}}}}},
+				}}},
+				{Incarnations: committed},
+			},
+			"trace: txn 0 reads the version of txn 1",
+		},
+		{
+			"no incarnation",
+			[]TxnTrace{{}},
+			"trace: txn 0 has no incarnation",
+		},
+		{
+			"aborted last incarnation",
+			[]TxnTrace{{Incarnations: []IncarnationTrace{{Conflict: &ReadConflict{Reason: AbortReadEstimate}}}}},
+			"trace: txn 0: only the last incarnation is committed",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, WriteBlockTrace(&buf, &BlockTrace{Stores: []StoreTrace{{Name: "acc"}}, Txs: tc.txs}))
+			_, err := ReadBlockTrace(&buf)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestBlockTraceCustomValueType(t *testing.T) {
+	key := &slotStoreKey{"slots"}
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, key: 1}
+	blk := testBlock(10, 10)
+
+	err := ExecuteBlock(
+		context.Background(), blk.Size(), stores, NewMultiMemDB(stores), 10, blk.ExecuteTx,
+		WithTrace(func(*BlockTrace) {}),
+	)
+	require.EqualError(t, err, "can't trace store slots of custom value type")
+
+	mv := NewMVMemory(0, stores, NewMultiMemDB(stores), nil)
+	require.NotNil(t, recoverPanic(func() { NewBlockTrace(mv) }))
+}
@@ -54,6 +54,26 @@ type ReadSet struct {
 
 type MultiReadSet = map[int]*ReadSet
 
+// AbortReason is why a validation failed.
+type AbortReason uint8
+
+const (
+	// AbortReadChanged means a read observes a different version now, i.e. a lower transaction wrote or deleted the key.
+	AbortReadChanged AbortReason = iota + 1
+	// AbortReadEstimate means a read observes an estimate now, i.e. the writer is aborted.
+	AbortReadEstimate
+	// AbortIteratorChanged means an iteration observes different keys or versions now.
+	AbortIteratorChanged
+)
+
+// ReadConflict locates the first invalid read found by a failed validation.
+type ReadConflict struct {
+	Reason AbortReason
+	Store  int
+	// Key is the read key, or the start of the iterator.
+	Key Key
+}
+
 type KeyItem interface {
 	GetKey() []byte
 }
@@ -76,7 +96,17 @@ type MVStore interface {
 	Delete(Key, TxnIndex)
 	WriteEstimate(Key, TxnIndex)
 	ValidateReadSet(TxnIndex, *ReadSet) bool
-	SnapshotToStore(storetypes.Store)
+	FindConflict(TxnIndex, *ReadSet) *ReadConflict
+	SnapshotToStore(store storetypes.Store, skipNoop bool) (written, skipped int)
+	TxnWrites(TxnIndex, Locations) []GKVPair[any]
+	MarshalValue(any) []byte
+	SnapshotMarshaled(func(Key, []byte) bool)
+	CopyTxnWrites(src MVStore, from TxnIndex, to TxnVersion, locations Locations) Locations
+
+	// NewMVView and NewReadOnlyView create the views of the value type, the storage must be a `GKVStore` of the same
+	// value type.
+	NewMVView(store int, storage storetypes.Store, scheduler *Scheduler, txn TxnIndex, opts ViewOptions) MVView
+	NewReadOnlyView(storage storetypes.Store, txn TxnIndex) storetypes.Store
 }
 
 // MVView is a value type agnostic interface for `MVMemoryView`, to keep `MultiMVMemoryView` value type agnostic.
@@ -85,4 +115,9 @@ type MVView interface {
 
 	ApplyWriteSet(TxnVersion) Locations
 	ReadSet() *ReadSet
+
+	// CheckpointWriteSet returns an opaque snapshot of the current write set,
+	// which can be restored with `RevertWriteSet`, the read set is not affected.
+	CheckpointWriteSet() any
+	RevertWriteSet(any)
 }
@@ -14,6 +14,21 @@ func (e ErrReadError) Error() string {
 	return fmt.Sprintf("read error: blocked by txn %d", e.BlockingTxn)
 }
 
+// ErrUnknownStore is the panic value when a transaction accesses a store which is not in the stores of the block,
+// `ExecuteBlock` recovers it and returns it as the error, it's also returned by `ValidateStores` with `Parent` set if
+// the parent storage doesn't have the store.
+type ErrUnknownStore struct {
+	Store  string
+	Parent bool
+}
+
+func (e ErrUnknownStore) Error() string {
+	if e.Parent {
+		return fmt.Sprintf("store %s is missing in the parent storage", e.Store)
+	}
+	return fmt.Sprintf("unknown store %s, it must be included in the stores of the block", e.Store)
+}
+
 // StoreMin implements a compare-and-swap operation that stores the minimum of the current value and the given value.
 func StoreMin(a *atomic.Uint64, b uint64) {
 	for {
@@ -0,0 +1,166 @@
+package block_stm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	storetypes "cosmossdk.io/store/types"
+)
+
+// TxnAccess is the written locations of the final incarnation of a transaction, the read sets are not shipped, since
+// the followers validate the read sets of their own executions.
+type TxnAccess struct {
+	Writes MultiLocations
+}
+
+// Accesses returns the accesses of all the transactions in order, should only be called after the block is executed,
+// e.g. the proposer ships them to the followers to run `ExecuteBlockValidateOnly`.
+func (mv *MVMemory) Accesses() []TxnAccess {
+	accesses := make([]TxnAccess, mv.BlockSize())
+	for txn := range accesses {
+		accesses[txn].Writes = mv.readLastWrittenLocations(TxnIndex(txn))
+	}
+	return accesses
+}
+
+// ExecuteBlockValidateOnly executes the block with the accesses recorded by another node, e.g. the proposer.
+//
+// The written locations are converted to estimates, so each transaction waits for the writers it depends on, and is
+// executed exactly once without validation. After all the transactions are executed, the read sets are validated once
+// against the final versions, on any mismatch, it falls back to normal Block-STM, reusing the executed results.
+func ExecuteBlockValidateOnly(
+	ctx context.Context,
+	blockSize int,
+	stores map[storetypes.StoreKey]int,
+	storage MultiStore,
+	executors int,
+	accesses []TxnAccess,
+	txExecutor TxExecutor,
+	opts ...Option,
+) error {
+	if executors < 0 {
+		return fmt.Errorf("invalid number of executors: %d", executors)
+	}
+	if executors == 0 {
+		executors = maxParallelism()
+	}
+	if len(accesses) != blockSize {
+		return fmt.Errorf("accesses length %d don't match block size %d", len(accesses), blockSize)
+	}
+	if err := ValidateStores(stores, storage); err != nil {
+		return err
+	}
+	o := newOptions(opts)
+	if err := o.validate(stores); err != nil {
+		return err
+	}
+
+	// the accesses are untrusted, they must not panic the node
+	estimates := make([]MultiLocations, blockSize)
+	for txn, access := range accesses {
+		if err := validateLocations(access.Writes, len(stores)); err != nil {
+			return fmt.Errorf("invalid writes of txn %d: %w", txn, err)
+		}
+		estimates[txn] = access.Writes
+	}
+
+	scheduler := NewScheduler(blockSize)
+	mvMemory := NewMVMemoryWithEstimates(blockSize, stores, storage, scheduler, estimates)
+	mvMemory.SetViewOptions(o.viewOptions)
+
+	parallelFor(ctx, blockSize, executors, func(txn TxnIndex) bool {
+		incarnation, ok := scheduler.txn_status[txn].TrySetExecuting()
+		if !ok {
+			panic(fmt.Sprintf("txn %d is not ready to execute", txn))
+		}
+		scheduler.executedTxns.Add(1)
+
+		version := TxnVersion{txn, incarnation}
+		view := mvMemory.View(txn)
+		mvMemory.ExecuteTx(txExecutor, txn, view)
+		mvMemory.Record(version, view)
+		scheduler.SetExecuted(txn)
+		return true
+	})
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	var invalid atomic.Bool
+	parallelFor(ctx, blockSize, executors, func(txn TxnIndex) bool {
+		scheduler.validatedTxns.Add(1)
+		if !mvMemory.ValidateReadSet(txn) {
+			invalid.Store(true)
+			return false
+		}
+		return true
+	})
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if !invalid.Load() {
+		return finishBlock(mvMemory, storage, o)
+	}
+
+	// fallback to Block-STM, all the transactions are executed and pending validation
+	stats := scheduler.Metrics()
+	scheduler = NewScheduler(blockSize)
+	scheduler.addMetrics(stats)
+	for txn := 0; txn < blockSize; txn++ {
+		scheduler.SetExecuted(TxnIndex(txn))
+	}
+	mvMemory.scheduler = scheduler
+	return runBlock(ctx, scheduler, mvMemory, storage, executors, txExecutor, o)
+}
+
+// validateLocations checks the store indexes are in range, and the keys of each store are strictly sorted.
+func validateLocations(locations MultiLocations, stores int) error {
+	for store, keys := range locations {
+		if store < 0 || store >= stores {
+			return fmt.Errorf("store index %d is out of range [0, %d)", store, stores)
+		}
+		if err := validateSortedKeys(keys); err != nil {
+			return fmt.Errorf("store %d: %w", store, err)
+		}
+	}
+	return nil
+}
+
+// validateSortedKeys checks the keys are strictly sorted, as required by `DiffOrderedList`.
+func validateSortedKeys(keys Locations) error {
+	for i := 1; i < len(keys); i++ {
+		if bytes.Compare(keys[i-1], keys[i]) >= 0 {
+			return fmt.Errorf("keys are not strictly sorted at %d: %x, %x", i, keys[i-1], keys[i])
+		}
+	}
+	return nil
+}
+
+// parallelFor calls fn for each txn index in order with the workers, stops when fn returns false or ctx is cancelled.
+func parallelFor(ctx context.Context, n, workers int, fn func(TxnIndex) bool) {
+	var (
+		next    atomic.Int64
+		stopped atomic.Bool
+		wg      sync.WaitGroup
+	)
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for !stopped.Load() && ctx.Err() == nil {
+				txn := next.Add(1) - 1
+				if txn >= int64(n) {
+					return
+				}
+				if !fn(TxnIndex(txn)) {
+					stopped.Store(true)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,90 @@
+package block_stm
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	storetypes "cosmossdk.io/store/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestExecuteBlockValidateOnly(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	testCases := []struct {
+		name string
+		blk  *MockBlock
+		// don't supply the accesses to trigger the fallback
+		noAccesses bool
+	}{
+		{"testBlock(100,80)", testBlock(100, 80), false},
+		{"testBlock(100,3)", testBlock(100, 3), false},
+		{"iterateBlock(100,10)", iterateBlock(100, 10), false},
+		{"worstCaseBlock(100)", worstCaseBlock(100), false},
+		{"testBlock(100,3)-fallback", testBlock(100, 3), true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// proposer
+			var accesses []TxnAccess
+			require.NoError(t, ExecuteBlock(
+				context.Background(), tc.blk.Size(), stores, NewMultiMemDB(stores), 10, tc.blk.ExecuteTx,
+				WithDryRun(func(p *PendingBlock) {
+					accesses = p.MVMemory().Accesses()
+				}),
+			))
+			if tc.noAccesses {
+				accesses = make([]TxnAccess, tc.blk.Size())
+			}
+
+			// follower
+			var (
+				executed atomic.Int64
+				stats    SchedulerStats
+			)
+			storage := NewMultiMemDB(stores)
+			require.NoError(t, ExecuteBlockValidateOnly(
+				context.Background(), tc.blk.Size(), stores, storage, 10, accesses,
+				func(txn TxnIndex, store MultiStore) {
+					executed.Add(1)
+					tc.blk.ExecuteTx(txn, store)
+				},
+				WithStats(func(s SchedulerStats) { stats = s }),
+			))
+			if !tc.noAccesses {
+				require.Equal(t, int64(tc.blk.Size()), executed.Load())
+			}
+			// the stats include both phases of the fallback
+			require.Equal(t, executed.Load(), stats.Executed)
+			require.NotZero(t, stats.Validated)
+
+			crossCheck := NewMultiMemDB(stores)
+			runSequential(crossCheck, tc.blk)
+			for store := range stores {
+				require.True(t, StoreEqual(crossCheck.GetKVStore(store), storage.GetKVStore(store)))
+			}
+		})
+	}
+}
+
+func TestExecuteBlockValidateOnlyInvalidAccesses(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	testCases := []struct {
+		writes MultiLocations
+		err    string
+	}{
+		{MultiLocations{2: {Key("a")}}, "invalid writes of txn 1: store index 2 is out of range [0, 2)"},
+		{MultiLocations{-1: {Key("a")}}, "invalid writes of txn 1: store index -1 is out of range [0, 2)"},
+		{MultiLocations{0: {Key("b"), Key("a")}}, "invalid writes of txn 1: store 0: keys are not strictly sorted at 1: 62, 61"},
+		{MultiLocations{1: {Key("a"), Key("a")}}, "invalid writes of txn 1: store 1: keys are not strictly sorted at 1: 61, 61"},
+	}
+	for _, tc := range testCases {
+		accesses := []TxnAccess{{}, {Writes: tc.writes}}
+		err := ExecuteBlockValidateOnly(
+			context.Background(), 2, stores, NewMultiMemDB(stores), 2, accesses,
+			func(TxnIndex, MultiStore) { t.Fatal("executed with invalid accesses") },
+		)
+		require.EqualError(t, err, tc.err)
+	}
+}
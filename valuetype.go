@@ -0,0 +1,153 @@
+package block_stm
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sync"
+
+	storetypes "cosmossdk.io/store/types"
+)
+
+// ValueType describes the values of a kind of stores, so the typed stores can be multi-versioned without boxing the
+// values into `any`, e.g. the fixed-size storage slots.
+type ValueType[V any] struct {
+	IsZero   func(V) bool
+	ValueLen func(V) int
+	// Clone copies a value for the `CopyOnRead` and `CopyOnWrite` view options, `nil` means the values are shared as is.
+	Clone func(V) V
+	// CloneByDefault applies `Clone` on read and write without the copy view options, unless `ViewOptions.ZeroCopy`,
+	// for the mutable values, e.g. the object values implementing `Cloner`, only the values are copied, not the keys.
+	CloneByDefault bool
+	// Equal compares the values to skip the no-op writes, see `WithSkipNoopWrites`, `nil` means the values are
+	// incomparable, only the deletions of the absent keys are skipped.
+	Equal func(V, V) bool
+	// Fingerprint hashes a value for the `Strict` view option, it must cover everything reachable from the value,
+	// `nil` means `DeepFingerprint`.
+	Fingerprint func(V) uint64
+	// Marshal encodes a non-zero value deterministically, for the digests, the change records and the determinism
+	// checks, it's required by `RegisterValueType`.
+	Marshal func(V) []byte
+}
+
+var (
+	BytesValueType = ValueType[[]byte]{
+		IsZero: BytesIsZero, ValueLen: BytesLen, Clone: bytes.Clone, Equal: bytes.Equal, Fingerprint: BytesFingerprint,
+		Marshal: func(v []byte) []byte { return v },
+	}
+	ObjValueType = ValueType[any]{IsZero: ObjIsZero, ValueLen: ObjLen, Clone: ObjClone, CloneByDefault: true}
+)
+
+// Cloner is implemented by the object values which can be copied, they are copied on read and write by default, so
+// they are not shared between the transactions, unless `WithZeroCopy`.
+type Cloner interface {
+	// Clone returns a deep copy of the value, of the same type.
+	Clone() any
+}
+
+// ObjClone copies the object value if it implements `Cloner`, otherwise returns it as is.
+func ObjClone(v any) any {
+	if c, ok := v.(Cloner); ok {
+		return c.Clone()
+	}
+	return v
+}
+
+// storeType is the value type agnostic interface of `ValueType`.
+type storeType interface {
+	NewMVStore() MVStore
+	NewMemDB() storetypes.Store
+
+	// storeDigest and divergedKeys compare the parent storages of the value type, see `CheckDeterminism`.
+	storeDigest(storetypes.Store) []byte
+	divergedKeys(a, b storetypes.Store) []Key
+}
+
+var (
+	storeTypesMtx sync.RWMutex
+	// store key type -> store type
+	storeTypes = map[reflect.Type]storeType{
+		reflect.TypeOf(&storetypes.ObjectStoreKey{}): ObjValueType,
+	}
+)
+
+// RegisterValueType registers the value type of the stores whose keys have the same dynamic type as `key`, e.g. a custom
+// `StoreKey` implementation, the parent storages of these stores must implement `storetypes.GKVStore[V]`, and the
+// `TxExecutor` access them with `GetStore(key).(storetypes.GKVStore[V])`.
+// The stores of the unregistered key types have `[]byte` values, it panics if `vt.Marshal` is `nil`, or the key type is
+// already registered.
+func RegisterValueType[V any](key storetypes.StoreKey, vt ValueType[V]) {
+	if vt.Marshal == nil {
+		panic(fmt.Sprintf("value type of store key type %T has no Marshal", key))
+	}
+
+	storeTypesMtx.Lock()
+	defer storeTypesMtx.Unlock()
+	if _, ok := storeTypes[reflect.TypeOf(key)]; ok {
+		panic(fmt.Sprintf("value type of store key type %T is already registered", key))
+	}
+	storeTypes[reflect.TypeOf(key)] = vt
+}
+
+func lookupStoreType(key storetypes.StoreKey) storeType {
+	storeTypesMtx.RLock()
+	defer storeTypesMtx.RUnlock()
+	if st, ok := storeTypes[reflect.TypeOf(key)]; ok {
+		return st
+	}
+	return BytesValueType
+}
+
+// isBytesStore returns if the store has `[]byte` values, only these stores and the object stores can be traced.
+func isBytesStore(key storetypes.StoreKey) bool {
+	_, ok := lookupStoreType(key).(ValueType[[]byte])
+	return ok
+}
+
+// isObjectStore returns if the store is an object store, the object stores are transient, so they have no encoding and
+// are excluded from the change records, digests and determinism checks.
+func isObjectStore(key storetypes.StoreKey) bool {
+	_, ok := key.(*storetypes.ObjectStoreKey)
+	return ok
+}
+
+func (vt ValueType[V]) NewMVStore() MVStore {
+	return vt.NewMVData()
+}
+
+func (vt ValueType[V]) NewMVData() *GMVData[V] {
+	d := NewGMVData(vt.IsZero, vt.ValueLen)
+	d.clone = vt.Clone
+	d.cloneByDefault = vt.CloneByDefault && vt.Clone != nil
+	d.equal = vt.Equal
+	d.fingerprint = vt.Fingerprint
+	d.marshal = vt.Marshal
+	return d
+}
+
+func (vt ValueType[V]) NewMemDB() storetypes.Store {
+	return NewGMemDB(vt.IsZero, vt.ValueLen)
+}
+
+func (vt ValueType[V]) storeDigest(store storetypes.Store) []byte {
+	return kvStoreDigest(store.(storetypes.GKVStore[V]), mustMarshal(vt.Marshal))
+}
+
+func (vt ValueType[V]) divergedKeys(a, b storetypes.Store) []Key {
+	return divergedKeys(a.(storetypes.GKVStore[V]), b.(storetypes.GKVStore[V]), mustMarshal(vt.Marshal))
+}
+
+// mustMarshal panics if the value type has no encoding, so the stores are never skipped silently, the encoded non-zero
+// values are never `nil`, which means deleted.
+func mustMarshal[V any](marshal func(V) []byte) func(V) []byte {
+	if marshal == nil {
+		var v V
+		panic(fmt.Sprintf("value type %T has no Marshal", v))
+	}
+	return func(v V) []byte {
+		if bz := marshal(v); bz != nil {
+			return bz
+		}
+		return []byte{}
+	}
+}
@@ -0,0 +1,139 @@
+package block_stm
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	storetypes "cosmossdk.io/store/types"
+	"github.com/test-go/testify/require"
+)
+
+// slotStoreKey is the key type of the stores of fixed-size storage slots.
+type slotStoreKey struct {
+	name string
+}
+
+func (key *slotStoreKey) Name() string   { return key.name }
+func (key *slotStoreKey) String() string { return key.name }
+
+type slot = [32]byte
+
+var slotValueType = ValueType[*slot]{
+	IsZero:   func(v *slot) bool { return v == nil },
+	ValueLen: func(*slot) int { return len(slot{}) },
+	Clone: func(v *slot) *slot {
+		c := *v
+		return &c
+	},
+	Marshal: func(v *slot) []byte { return v[:] },
+}
+
+func init() {
+	RegisterValueType(&slotStoreKey{}, slotValueType)
+}
+
+func TestValueTypeRegistry(t *testing.T) {
+	key := &slotStoreKey{"slots"}
+	require.IsType(t, &GMVData[*slot]{}, NewMVStore(key))
+	require.IsType(t, &GMVData[[]byte]{}, NewMVStore(StoreKeyAuth))
+	require.IsType(t, &GMVData[any]{}, NewMVStore(storetypes.NewObjectStoreKey("obj")))
+
+	require.True(t, isBytesStore(StoreKeyAuth))
+	require.False(t, isBytesStore(key))
+	require.False(t, isBytesStore(storetypes.NewObjectStoreKey("obj")))
+
+	stores := map[storetypes.StoreKey]int{key: 0}
+	storage := NewMultiMemDB(stores)
+	require.IsType(t, &GMemDB[*slot]{}, storage.GetStore(key))
+}
+
+func TestExecuteBlockTypedStore(t *testing.T) {
+	key := &slotStoreKey{"slots"}
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, key: 1}
+
+	// each tx increments a counter slot and records the previous value in the auth store
+	const blockSize = 100
+	var txs []Tx
+	for i := 0; i < blockSize; i++ {
+		counter := []byte{byte(i % 3)}
+		txs = append(txs, func(store MultiStore) error {
+			slots := store.GetStore(key).(storetypes.GKVStore[*slot])
+			var n uint64
+			if v := slots.Get(counter); v != nil {
+				n = binary.BigEndian.Uint64(v[:])
+				// mutating the value in place is safe with copy-on-read
+				v[0] = 0xff
+			}
+			var v slot
+			binary.BigEndian.PutUint64(v[:], n+1)
+			slots.Set(counter, &v)
+			store.GetKVStore(StoreKeyAuth).Set(Key{byte(i)}, binary.BigEndian.AppendUint64(nil, n))
+			return nil
+		})
+	}
+
+	for _, executors := range []int{1, 4, 8} {
+		storage := NewMultiMemDB(stores)
+		require.NoError(t, ExecuteBlock(
+			context.Background(), blockSize, stores, storage, executors, NewMockBlock(txs).ExecuteTx,
+			WithViewOptions(ViewOptions{CopyOnRead: true}),
+		))
+
+		slots := storage.GetStore(key).(storetypes.GKVStore[*slot])
+		for i := 0; i < 3; i++ {
+			v := slots.Get([]byte{byte(i)})
+			require.NotNil(t, v)
+			require.Equal(t, uint64((blockSize-i+2)/3), binary.BigEndian.Uint64(v[:]))
+		}
+	}
+}
+
+func TestRegisterValueTypeTwice(t *testing.T) {
+	require.Equal(t, "value type of store key type *block_stm.slotStoreKey is already registered", recoverPanic(func() {
+		RegisterValueType(&slotStoreKey{}, slotValueType)
+	}))
+	require.Equal(t, "value type of store key type *types.ObjectStoreKey is already registered", recoverPanic(func() {
+		RegisterValueType(storetypes.NewObjectStoreKey("obj"), ValueType[any]{
+			IsZero: ObjIsZero, ValueLen: ObjLen, Marshal: func(any) []byte { return nil },
+		})
+	}))
+}
+
+func TestValueTypeMarshal(t *testing.T) {
+	require.NotNil(t, recoverPanic(func() {
+		RegisterValueType(&slotStoreKey{}, ValueType[*slot]{IsZero: slotValueType.IsZero, ValueLen: slotValueType.ValueLen})
+	}))
+
+	key := &slotStoreKey{"slots"}
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, key: 1, StoreKeyObj: 2}
+	write := func(v byte) func(TxnIndex, MultiStore) {
+		return func(txn TxnIndex, store MultiStore) {
+			store.GetStore(key).(storetypes.GKVStore[*slot]).Set(Key("a"), &slot{v})
+			store.GetStore(key).(storetypes.GKVStore[*slot]).Delete(Key("b"))
+			store.GetObjKVStore(StoreKeyObj).Set(Key("o"), v)
+		}
+	}
+
+	var (
+		digest  []byte
+		changes []*storetypes.StoreKVPair
+	)
+	require.NoError(t, ExecuteBlock(
+		context.Background(), 1, stores, NewMultiMemDB(stores), 1, write(1),
+		WithDigest(func(d []byte) { digest = d }),
+		WithChangeListener(func(_ TxnIndex, c []*storetypes.StoreKVPair) { changes = c }),
+	))
+	// the typed store is encoded, the object store is skipped
+	require.Equal(t, []*storetypes.StoreKVPair{
+		{StoreKey: "slots", Key: Key("a"), Value: (&slot{1})[:]},
+		{StoreKey: "slots", Delete: true, Key: Key("b")},
+	}, changes)
+
+	var other []byte
+	require.NoError(t, ExecuteBlock(
+		context.Background(), 1, stores, NewMultiMemDB(stores), 1, write(2),
+		WithDigest(func(d []byte) { other = d }),
+	))
+	require.NotEqual(t, digest, other)
+}
@@ -0,0 +1,163 @@
+package block_stm
+
+import (
+	"bytes"
+	"fmt"
+
+	storetypes "cosmossdk.io/store/types"
+)
+
+// ViewOptions configures how the transaction views handle the values shared with the other transactions, the values
+// read from the multi-version data or the parent storage are not copied by default, and the `TxExecutor` must not
+// mutate them.
+type ViewOptions struct {
+	// Strict fingerprints the shared values on read, and the last value written to each key, then verifies them when the
+	// transaction finishes, panics with the key and txn index if any of them is mutated, it's expensive and meant for
+	// debugging.
+	// The values are fingerprinted with `ValueType.Fingerprint`, e.g. `DeepFingerprint` for the object values.
+	Strict bool
+	// CopyOnRead returns copies of the shared values, so the `TxExecutor` is free to mutate them,
+	// the values without `ValueType.Clone`, and the object values not implementing `Cloner`, are returned as is.
+	CopyOnRead bool
+	// CopyOnWrite copies the keys and values passed to `Set` and `Delete`, so the caller is free to reuse the buffers,
+	// the values without `ValueType.Clone`, and the object values not implementing `Cloner`, are stored as is.
+	CopyOnWrite bool
+	// ZeroCopy disables the default copies of the value types with `ValueType.CloneByDefault`, e.g. the object values
+	// implementing `Cloner`.
+	ZeroCopy bool
+}
+
+// DefaultViewOptions copies on write in debug builds, see `DebugBuild`, otherwise it's zero-copy.
+func DefaultViewOptions() ViewOptions {
+	return ViewOptions{CopyOnWrite: DebugBuild}
+}
+
+// WithViewOptions enables the set fields of `ViewOptions` on top of the defaults, e.g. `ViewOptions{Strict: true}`
+// keeps the copy-on-write default of the debug builds, use `WithZeroCopy` to disable the copies.
+func WithViewOptions(viewOptions ViewOptions) Option {
+	return func(o *options) {
+		o.viewOptions.Strict = o.viewOptions.Strict || viewOptions.Strict
+		o.viewOptions.CopyOnRead = o.viewOptions.CopyOnRead || viewOptions.CopyOnRead
+		o.viewOptions.CopyOnWrite = o.viewOptions.CopyOnWrite || viewOptions.CopyOnWrite
+		o.viewOptions.ZeroCopy = o.viewOptions.ZeroCopy || viewOptions.ZeroCopy
+	}
+}
+
+// WithZeroCopy disables the copies even in debug builds, the caller must follow the zero-copy contract:
+//   - don't mutate the keys and values returned by the views, including the iterators.
+//   - don't mutate or reuse the keys and values passed to `Set` and `Delete` after the calls, they are stored as is.
+func WithZeroCopy() Option {
+	return func(o *options) {
+		o.viewOptions.CopyOnRead = false
+		o.viewOptions.CopyOnWrite = false
+		o.viewOptions.ZeroCopy = true
+	}
+}
+
+// valueFingerprint records a value observed or written by the transaction and its fingerprint at the time, the zero
+// value records a deletion.
+type valueFingerprint[V any] struct {
+	key   Key
+	value V
+	sum   uint64
+}
+
+func (s *GMVMemoryView[V]) fingerprintValue(value V) uint64 {
+	if s.mvData.fingerprint != nil {
+		return s.mvData.fingerprint(value)
+	}
+	return DeepFingerprint(value)
+}
+
+// cloneByDefault returns if the values are copied on read and write without the copy view options.
+func (s *GMVMemoryView[V]) cloneByDefault() bool {
+	return s.mvData.cloneByDefault && !s.opts.ZeroCopy
+}
+
+func (s *GMVMemoryView[V]) cloneValue(value V) V {
+	if s.mvData.clone == nil {
+		return value
+	}
+	return s.mvData.clone(value)
+}
+
+// sharedValue applies the view options to a value read from the shared state.
+func (s *GMVMemoryView[V]) sharedValue(key Key, value V) V {
+	if s.mvData.isZero(value) {
+		return value
+	}
+	if s.opts.Strict {
+		s.fingerprint(key, value)
+	}
+	return s.copySharedValue(value)
+}
+
+func (s *GMVMemoryView[V]) copySharedValue(value V) V {
+	if s.opts.CopyOnRead || s.cloneByDefault() {
+		return s.cloneValue(value)
+	}
+	return value
+}
+
+func (s *GMVMemoryView[V]) fingerprint(key Key, value V) {
+	s.fingerprints = append(s.fingerprints, valueFingerprint[V]{key, value, s.fingerprintValue(value)})
+}
+
+// fingerprintWrite records a write, it supersedes the previous writes of the key, because the txn is free to mutate
+// its own writes before writing them again.
+func (s *GMVMemoryView[V]) fingerprintWrite(key Key, value V) {
+	var sum uint64
+	if !s.mvData.isZero(value) {
+		sum = s.fingerprintValue(value)
+	}
+	s.writeFingerprints = append(s.writeFingerprints, valueFingerprint[V]{key, value, sum})
+}
+
+// verifyFingerprints panics if any of the observed values, or the last written value of any key, is mutated.
+func (s *GMVMemoryView[V]) verifyFingerprints() {
+	for _, fp := range s.fingerprints {
+		s.verifyFingerprint(fp)
+	}
+
+	written := make(map[string]struct{}, len(s.writeFingerprints))
+	for i := len(s.writeFingerprints) - 1; i >= 0; i-- {
+		fp := s.writeFingerprints[i]
+		if _, ok := written[string(fp.key)]; ok {
+			continue
+		}
+		written[string(fp.key)] = struct{}{}
+		if !s.mvData.isZero(fp.value) {
+			s.verifyFingerprint(fp)
+		}
+	}
+}
+
+func (s *GMVMemoryView[V]) verifyFingerprint(fp valueFingerprint[V]) {
+	if s.fingerprintValue(fp.value) != fp.sum {
+		panic(fmt.Sprintf("value of key %x in store %d observed by txn %d is mutated", fp.key, s.store, s.txn))
+	}
+}
+
+// sharedValueIterator applies the view options to the values of the iterator, the values written by the txn are
+// returned as is, like `Get`.
+type sharedValueIterator[V any] struct {
+	storetypes.GIterator[V]
+	view *GMVMemoryView[V]
+
+	// the last key fingerprinted, so calling `Value` repeatedly records the key only once
+	fingerprinted Key
+}
+
+func (it *sharedValueIterator[V]) Value() V {
+	key, value := it.Key(), it.GIterator.Value()
+	if it.view.writeSet != nil {
+		if _, found := it.view.writeSet.OverlayGet(key); found {
+			return value
+		}
+	}
+	if it.view.opts.Strict && bytes.Equal(key, it.fingerprinted) {
+		return it.view.copySharedValue(value)
+	}
+	it.fingerprinted = key
+	return it.view.sharedValue(key, value)
+}
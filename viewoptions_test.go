@@ -0,0 +1,227 @@
+package block_stm
+
+import (
+	"context"
+	"testing"
+
+	storetypes "cosmossdk.io/store/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestViewOptionsStrict(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0}
+	storage := NewMultiMemDB(stores)
+	storage.GetKVStore(StoreKeyAuth).Set(Key("p"), []byte("0"))
+	mv := NewMVMemory(16, stores, storage, nil)
+	mv.SetViewOptions(ViewOptions{Strict: true})
+
+	mview := mv.View(0)
+	mview.GetKVStore(StoreKeyAuth).Set(Key("a"), []byte("1"))
+	require.True(t, mv.Record(TxnVersion{0, 0}, mview))
+
+	// mutate the value written by another txn
+	mview = mv.View(1)
+	mview.GetKVStore(StoreKeyAuth).Get(Key("a"))[0] = '2'
+	require.Equal(t, "value of key 61 in store 0 observed by txn 1 is mutated", recoverPanic(func() { mv.Record(TxnVersion{1, 0}, mview) }))
+
+	// mutate the value of the parent storage through an iterator
+	mview = mv.View(2)
+	it := mview.GetKVStore(StoreKeyAuth).Iterator(Key("p"), nil)
+	it.Value()[0] = '1'
+	require.NoError(t, it.Close())
+	require.Equal(t, "value of key 70 in store 0 observed by txn 2 is mutated", recoverPanic(func() { mv.Record(TxnVersion{2, 0}, mview) }))
+
+	// mutate the buffer after write
+	mview = mv.View(3)
+	buf := []byte("3")
+	mview.GetKVStore(StoreKeyAuth).Set(Key("b"), buf)
+	buf[0] = '4'
+	require.Equal(t, "value of key 62 in store 0 observed by txn 3 is mutated", recoverPanic(func() { mv.Record(TxnVersion{3, 0}, mview) }))
+}
+
+func TestViewOptionsStrictNested(t *testing.T) {
+	type inner struct{ n int }
+	type outer struct{ inner *inner }
+
+	stores := map[storetypes.StoreKey]int{StoreKeyObj: 0}
+	mv := NewMVMemory(16, stores, NewMultiMemDB(stores), nil)
+	mv.SetViewOptions(ViewOptions{Strict: true})
+
+	mview := mv.View(0)
+	mview.GetObjKVStore(StoreKeyObj).Set(Key("a"), outer{&inner{1}})
+	require.True(t, mv.Record(TxnVersion{0, 0}, mview))
+
+	// mutate the value behind the pointer
+	mview = mv.View(1)
+	mview.GetObjKVStore(StoreKeyObj).Get(Key("a")).(outer).inner.n = 2
+	require.Equal(t, "value of key 61 in store 0 observed by txn 1 is mutated", recoverPanic(func() { mv.Record(TxnVersion{1, 0}, mview) }))
+}
+
+func TestViewOptionsStrictOwnWrites(t *testing.T) {
+	type counter struct{ n int }
+
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyObj: 1}
+	storage := NewMultiMemDB(stores)
+	storage.GetKVStore(StoreKeyAuth).Set(Key("p"), []byte("0"))
+	mv := NewMVMemory(16, stores, storage, nil)
+	mv.SetViewOptions(ViewOptions{Strict: true})
+
+	// mutate the own write and write it again
+	mview := mv.View(0)
+	objs := mview.GetObjKVStore(StoreKeyObj)
+	objs.Set(Key("a"), &counter{1})
+	objs.Get(Key("a")).(*counter).n = 2
+	objs.Set(Key("a"), objs.Get(Key("a")))
+	mview.GetKVStore(StoreKeyAuth).Set(Key("a"), []byte("1"))
+	require.True(t, mv.Record(TxnVersion{0, 0}, mview))
+
+	// reuse the buffers of the deleted and reverted writes
+	mview = mv.View(1)
+	store := mview.GetKVStore(StoreKeyAuth)
+	buf := []byte("1")
+	store.Set(Key("b"), buf)
+	store.Delete(Key("b"))
+	buf[0] = '2'
+	checkpoint := mview.Checkpoint()
+	store.Set(Key("c"), buf)
+	mview.RevertTo(checkpoint)
+	buf[0] = '3'
+	require.True(t, mv.Record(TxnVersion{1, 0}, mview))
+
+	// the writes before the checkpoint are still verified
+	mview = mv.View(2)
+	store = mview.GetKVStore(StoreKeyAuth)
+	store.Set(Key("d"), buf)
+	checkpoint = mview.Checkpoint()
+	store.Set(Key("d"), []byte("4"))
+	mview.RevertTo(checkpoint)
+	buf[0] = '4'
+	require.Equal(t, "value of key 64 in store 0 observed by txn 2 is mutated", recoverPanic(func() { mv.Record(TxnVersion{2, 0}, mview) }))
+
+	// the iterator fingerprints each shared value once, and not the own writes
+	mview = mv.View(3)
+	store = mview.GetKVStore(StoreKeyAuth)
+	store.Set(Key("e"), []byte("5"))
+	it := store.Iterator(nil, nil)
+	for ; it.Valid(); it.Next() {
+		it.Value()
+		it.Value()
+	}
+	require.NoError(t, it.Close())
+	var keys []Key
+	for _, fp := range mview.GetStore(StoreKeyAuth).(*GMVMemoryView[[]byte]).fingerprints {
+		keys = append(keys, fp.key)
+	}
+	require.Equal(t, []Key{Key("a"), Key("p")}, keys)
+}
+
+func TestDeepFingerprint(t *testing.T) {
+	type node struct {
+		value int
+		next  *node
+		attrs map[string]any
+	}
+
+	a := &node{value: 1, attrs: map[string]any{"x": []int{1}, "y": "2"}}
+	b := &node{value: 1, attrs: map[string]any{"y": "2", "x": []int{1}}}
+	require.Equal(t, DeepFingerprint(a), DeepFingerprint(b))
+
+	a.attrs["x"].([]int)[0] = 2
+	require.NotEqual(t, DeepFingerprint(a), DeepFingerprint(b))
+
+	// the cycles terminate
+	a.next = a
+	sum := DeepFingerprint(a)
+	a.value = 2
+	require.NotEqual(t, sum, DeepFingerprint(a))
+
+	self := map[string]any{}
+	self["self"] = self
+	DeepFingerprint(self)
+
+	require.NotEqual(t, DeepFingerprint([]byte(nil)), DeepFingerprint([]byte{}))
+	require.Equal(t, BytesFingerprint([]byte("a")), BytesFingerprint([]byte("a")))
+}
+
+func TestWithViewOptions(t *testing.T) {
+	o := newOptions([]Option{WithViewOptions(ViewOptions{Strict: true})})
+	require.Equal(t, ViewOptions{Strict: true, CopyOnWrite: DebugBuild}, o.viewOptions)
+
+	o = newOptions([]Option{WithViewOptions(ViewOptions{Strict: true, CopyOnRead: true}), WithZeroCopy()})
+	require.Equal(t, ViewOptions{Strict: true, ZeroCopy: true}, o.viewOptions)
+}
+
+func TestViewOptionsCopyOnRead(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0}
+	storage := NewMultiMemDB(stores)
+	storage.GetKVStore(StoreKeyAuth).Set(Key("p"), []byte("0"))
+	mv := NewMVMemory(16, stores, storage, nil)
+	mv.SetViewOptions(ViewOptions{Strict: true, CopyOnRead: true})
+
+	mview := mv.View(0)
+	mview.GetKVStore(StoreKeyAuth).Set(Key("a"), []byte("1"))
+	require.True(t, mv.Record(TxnVersion{0, 0}, mview))
+
+	mview = mv.View(1)
+	store := mview.GetKVStore(StoreKeyAuth)
+	store.Get(Key("a"))[0] = '2'
+	it := store.Iterator(nil, nil)
+	for ; it.Valid(); it.Next() {
+		it.Value()[0] = '2'
+	}
+	require.NoError(t, it.Close())
+	mv.Record(TxnVersion{1, 0}, mview)
+
+	store = mv.View(2).GetKVStore(StoreKeyAuth)
+	require.Equal(t, []byte("1"), store.Get(Key("a")))
+	require.Equal(t, []byte("0"), store.Get(Key("p")))
+}
+
+func TestViewOptionsCopyOnWrite(t *testing.T) {
+	require.Equal(t, DebugBuild, DefaultViewOptions().CopyOnWrite)
+
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0}
+	storage := NewMultiMemDB(stores)
+	mv := NewMVMemory(16, stores, storage, nil)
+	mv.SetViewOptions(ViewOptions{CopyOnWrite: true})
+
+	mview := mv.View(0)
+	store := mview.GetKVStore(StoreKeyAuth)
+	key, value := []byte("a"), []byte("1")
+	store.Set(key, value)
+	key[0], value[0] = 'b', '2'
+	store.Set(key, value)
+	key[0] = 'c'
+	store.Delete(key)
+	key[0] = 'd'
+	require.True(t, mv.Record(TxnVersion{0, 0}, mview))
+	require.Equal(t, []Key{Key("a"), Key("b"), Key("c")}, []Key(mv.readLastWrittenLocations(0)[0]))
+
+	store = mv.View(1).GetKVStore(StoreKeyAuth)
+	require.Equal(t, []byte("1"), store.Get(Key("a")))
+	require.Equal(t, []byte("2"), store.Get(Key("b")))
+}
+
+func TestExecuteBlockStrict(t *testing.T) {
+	stores := map[storetypes.StoreKey]int{StoreKeyAuth: 0, StoreKeyBank: 1}
+	blk := iterateBlock(100, 20)
+	storage := NewMultiMemDB(stores)
+	require.NoError(t, ExecuteBlock(
+		context.Background(), blk.Size(), stores, storage, 4, blk.ExecuteTx,
+		WithViewOptions(ViewOptions{Strict: true}),
+	))
+
+	crossCheck := NewMultiMemDB(stores)
+	runSequential(crossCheck, blk)
+	for store := range stores {
+		require.True(t, StoreEqual(crossCheck.GetKVStore(store), storage.GetKVStore(store)))
+	}
+}
+
+func recoverPanic(fn func()) (r any) {
+	defer func() {
+		r = recover()
+	}()
+	fn()
+	return
+}
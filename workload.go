@@ -0,0 +1,303 @@
+package block_stm
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+
+	storetypes "cosmossdk.io/store/types"
+)
+
+type KeyDistribution int
+
+const (
+	// DistributionUniform picks the keys uniformly.
+	DistributionUniform KeyDistribution = iota
+	// DistributionZipfian picks the keys with Zipf's law, the smaller keys are hotter.
+	DistributionZipfian
+	// DistributionHotspot picks the hot keys with `HotspotRatio` probability, the other keys uniformly.
+	DistributionHotspot
+)
+
+// WorkloadSpec declares a synthetic workload, the transactions are generated deterministically from `Seed`.
+type WorkloadSpec struct {
+	Seed int64
+	Txs  int
+	// OpsPerTx is the number of store operations of each transaction.
+	OpsPerTx int
+	// Stores and ObjectStores are the numbers of kv stores and object stores.
+	Stores       int
+	ObjectStores int
+	// Keys is the size of the key space of each store.
+	Keys int
+
+	Distribution KeyDistribution
+	// ZipfS is the exponent of the Zipfian distribution, must be greater than 1.
+	ZipfS float64
+	// HotspotKeys is the number of hot keys of the hotspot distribution.
+	HotspotKeys  int
+	HotspotRatio float64
+
+	// ReadRatio is the probability of an operation to be a read, the others are writes, which are read-modify-write
+	// of a counter, or deletes with `DeleteRatio` probability.
+	ReadRatio   float64
+	DeleteRatio float64
+	// IteratorRatio is the probability of an operation to be an iteration over at most `IteratorRange` keys.
+	IteratorRatio float64
+	IteratorRange int
+	// ObjectRatio is the probability of an operation to be on the object stores.
+	ObjectRatio float64
+
+	// CPUCost is the number of sha256 rounds each transaction spends besides the store operations.
+	CPUCost int
+}
+
+// DefaultWorkloadSpec returns a balanced workload with uniform key distribution.
+func DefaultWorkloadSpec() WorkloadSpec {
+	return WorkloadSpec{
+		Txs:           1000,
+		OpsPerTx:      10,
+		Stores:        2,
+		Keys:          1000,
+		ZipfS:         1.1,
+		HotspotKeys:   10,
+		HotspotRatio:  0.5,
+		ReadRatio:     0.7,
+		DeleteRatio:   0.05,
+		IteratorRange: 10,
+		CPUCost:       100,
+	}
+}
+
+// Workload is a generated block with the stores it accesses.
+type Workload struct {
+	Spec   WorkloadSpec
+	Block  *MockBlock
+	Stores map[storetypes.StoreKey]int
+}
+
+type opKind int
+
+const (
+	opRead opKind = iota
+	opWrite
+	opDelete
+	opIterate
+	opReverseIterate
+)
+
+type workloadOp struct {
+	kind  opKind
+	store storetypes.StoreKey
+	obj   bool
+	key   []byte
+}
+
+// Validate checks the spec can generate a workload.
+func (spec WorkloadSpec) Validate() error {
+	for _, n := range []struct {
+		name  string
+		value int
+	}{
+		{"Txs", spec.Txs},
+		{"OpsPerTx", spec.OpsPerTx},
+		{"Stores", spec.Stores},
+		{"ObjectStores", spec.ObjectStores},
+		{"HotspotKeys", spec.HotspotKeys},
+		{"IteratorRange", spec.IteratorRange},
+		{"CPUCost", spec.CPUCost},
+	} {
+		if n.value < 0 {
+			return fmt.Errorf("workload: negative %s: %d", n.name, n.value)
+		}
+	}
+	for _, r := range []struct {
+		name  string
+		value float64
+	}{
+		{"HotspotRatio", spec.HotspotRatio},
+		{"ReadRatio", spec.ReadRatio},
+		{"DeleteRatio", spec.DeleteRatio},
+		{"IteratorRatio", spec.IteratorRatio},
+		{"ObjectRatio", spec.ObjectRatio},
+	} {
+		if !(r.value >= 0 && r.value <= 1) {
+			return fmt.Errorf("workload: %s %v is out of range [0, 1]", r.name, r.value)
+		}
+	}
+
+	if spec.Stores+spec.ObjectStores == 0 {
+		return fmt.Errorf("workload: requires at least one store")
+	}
+	if spec.Keys <= 0 {
+		return fmt.Errorf("workload: Keys must be positive: %d", spec.Keys)
+	}
+	switch spec.Distribution {
+	case DistributionUniform:
+	case DistributionZipfian:
+		if !(spec.ZipfS > 1) {
+			return fmt.Errorf("workload: ZipfS must be greater than 1: %v", spec.ZipfS)
+		}
+	case DistributionHotspot:
+		if spec.HotspotKeys == 0 {
+			return fmt.Errorf("workload: HotspotKeys must be positive")
+		}
+	default:
+		return fmt.Errorf("workload: unknown distribution %d", spec.Distribution)
+	}
+	return nil
+}
+
+// NewWorkload generates the block from the spec, returns an error if the spec is invalid.
+func NewWorkload(spec WorkloadSpec) (*Workload, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	stores := make(map[storetypes.StoreKey]int, spec.Stores+spec.ObjectStores)
+	kvKeys := make([]storetypes.StoreKey, spec.Stores)
+	for i := range kvKeys {
+		kvKeys[i] = storetypes.NewKVStoreKey(fmt.Sprintf("store%d", i))
+		stores[kvKeys[i]] = len(stores)
+	}
+	objKeys := make([]storetypes.StoreKey, spec.ObjectStores)
+	for i := range objKeys {
+		objKeys[i] = storetypes.NewObjectStoreKey(fmt.Sprintf("objstore%d", i))
+		stores[objKeys[i]] = len(stores)
+	}
+
+	g := rand.New(rand.NewSource(spec.Seed))
+	pickKey := keyPicker(g, spec)
+
+	txs := make([]Tx, spec.Txs)
+	for i := range txs {
+		ops := make([]workloadOp, spec.OpsPerTx)
+		for j := range ops {
+			op := &ops[j]
+			op.obj = len(kvKeys) == 0 || (len(objKeys) > 0 && g.Float64() < spec.ObjectRatio)
+			if op.obj {
+				op.store = objKeys[g.Intn(len(objKeys))]
+			} else {
+				op.store = kvKeys[g.Intn(len(kvKeys))]
+			}
+			op.key = workloadKey(pickKey())
+
+			switch r := g.Float64(); {
+			case r < spec.IteratorRatio:
+				op.kind = opIterate
+				if g.Intn(2) == 0 {
+					op.kind = opReverseIterate
+				}
+			case r < spec.IteratorRatio+spec.ReadRatio:
+				op.kind = opRead
+			case g.Float64() < spec.DeleteRatio:
+				op.kind = opDelete
+			default:
+				op.kind = opWrite
+			}
+		}
+		txs[i] = workloadTx(ops, spec)
+	}
+
+	return &Workload{
+		Spec:   spec,
+		Block:  NewMockBlock(txs),
+		Stores: stores,
+	}, nil
+}
+
+func keyPicker(g *rand.Rand, spec WorkloadSpec) func() uint64 {
+	keys := uint64(spec.Keys)
+	switch spec.Distribution {
+	case DistributionZipfian:
+		zipf := rand.NewZipf(g, spec.ZipfS, 1, keys-1)
+		return zipf.Uint64
+	case DistributionHotspot:
+		hot := uint64(min(spec.HotspotKeys, spec.Keys))
+		return func() uint64 {
+			if g.Float64() < spec.HotspotRatio {
+				return uint64(g.Int63n(int64(hot)))
+			}
+			return uint64(g.Int63n(int64(keys)))
+		}
+	default:
+		return func() uint64 {
+			return uint64(g.Int63n(int64(keys)))
+		}
+	}
+}
+
+func workloadKey(i uint64) []byte {
+	return []byte(fmt.Sprintf("key%010d", i))
+}
+
+func workloadTx(ops []workloadOp, spec WorkloadSpec) Tx {
+	return func(store MultiStore) error {
+		burnCPU(spec.CPUCost)
+		for _, op := range ops {
+			if op.obj {
+				runObjOp(store.GetObjKVStore(op.store), op, spec.IteratorRange)
+			} else {
+				runKVOp(store.GetKVStore(op.store), op, spec.IteratorRange)
+			}
+		}
+		return nil
+	}
+}
+
+func runKVOp(store storetypes.KVStore, op workloadOp, iterRange int) {
+	switch op.kind {
+	case opRead:
+		store.Get(op.key)
+	case opWrite:
+		var counter uint64
+		if v := store.Get(op.key); v != nil {
+			counter = binary.BigEndian.Uint64(v)
+		}
+		store.Set(op.key, binary.BigEndian.AppendUint64(nil, counter+1))
+	case opDelete:
+		store.Delete(op.key)
+	case opIterate, opReverseIterate:
+		iterateWorkload(store, op, iterRange)
+	}
+}
+
+func runObjOp(store storetypes.ObjKVStore, op workloadOp, iterRange int) {
+	switch op.kind {
+	case opRead:
+		store.Get(op.key)
+	case opWrite:
+		var counter uint64
+		if v := store.Get(op.key); v != nil {
+			counter = v.(uint64)
+		}
+		store.Set(op.key, counter+1)
+	case opDelete:
+		store.Delete(op.key)
+	case opIterate, opReverseIterate:
+		iterateWorkload(store, op, iterRange)
+	}
+}
+
+func iterateWorkload[V any](store storetypes.GKVStore[V], op workloadOp, iterRange int) {
+	var it storetypes.GIterator[V]
+	if op.kind == opIterate {
+		it = store.Iterator(op.key, nil)
+	} else {
+		it = store.ReverseIterator(nil, op.key)
+	}
+	defer it.Close()
+
+	for i := 0; i < iterRange && it.Valid(); i++ {
+		it.Value()
+		it.Next()
+	}
+}
+
+func burnCPU(rounds int) {
+	var h [32]byte
+	for i := 0; i < rounds; i++ {
+		h = sha256.Sum256(h[:])
+	}
+}
@@ -0,0 +1,111 @@
+package block_stm
+
+import (
+	"context"
+	"testing"
+
+	storetypes "cosmossdk.io/store/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestWorkload(t *testing.T) {
+	uniform := DefaultWorkloadSpec()
+	uniform.Txs = 200
+
+	zipf := uniform
+	zipf.Distribution = DistributionZipfian
+	zipf.Keys = 50
+
+	hotspot := uniform
+	hotspot.Distribution = DistributionHotspot
+	hotspot.HotspotKeys = 3
+	hotspot.HotspotRatio = 0.9
+
+	objects := uniform
+	objects.Stores = 1
+	objects.ObjectStores = 2
+	objects.ObjectRatio = 0.5
+	objects.IteratorRatio = 0.2
+
+	objectOnly := objects
+	objectOnly.Stores = 0
+
+	testCases := []struct {
+		name string
+		spec WorkloadSpec
+	}{
+		{"uniform", uniform},
+		{"zipf", zipf},
+		{"hotspot", hotspot},
+		{"objects", objects},
+		{"object-only", objectOnly},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := NewWorkload(tc.spec)
+			require.NoError(t, err)
+			require.Equal(t, tc.spec.Txs, w.Block.Size())
+			require.Equal(t, tc.spec.Stores+tc.spec.ObjectStores, len(w.Stores))
+
+			sequential := NewMultiMemDB(w.Stores)
+			runSequential(sequential, w.Block)
+
+			for _, workers := range []int{1, 4, 16} {
+				storage := NewMultiMemDB(w.Stores)
+				require.NoError(t, ExecuteBlock(context.Background(), w.Block.Size(), w.Stores, storage, workers, w.Block.ExecuteTx))
+				for key := range w.Stores {
+					if _, ok := key.(*storetypes.ObjectStoreKey); ok {
+						require.True(t, objStoreEqual(sequential.GetObjKVStore(key), storage.GetObjKVStore(key)), key.Name())
+					} else {
+						require.True(t, StoreEqual(sequential.GetKVStore(key), storage.GetKVStore(key)), key.Name())
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWorkloadInvalidSpec(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		modify func(*WorkloadSpec)
+		err    string
+	}{
+		{"no store", func(s *WorkloadSpec) { s.Stores = 0 }, "workload: requires at least one store"},
+		{"no key", func(s *WorkloadSpec) { s.Keys = 0 }, "workload: Keys must be positive: 0"},
+		{"negative txs", func(s *WorkloadSpec) { s.Txs = -1 }, "workload: negative Txs: -1"},
+		{"negative stores", func(s *WorkloadSpec) { s.Stores, s.ObjectStores = -1, 2 }, "workload: negative Stores: -1"},
+		{"ratio", func(s *WorkloadSpec) { s.ReadRatio = 1.5 }, "workload: ReadRatio 1.5 is out of range [0, 1]"},
+		{"zipf", func(s *WorkloadSpec) {
+			s.Distribution = DistributionZipfian
+			s.ZipfS = 1
+		}, "workload: ZipfS must be greater than 1: 1"},
+		{"hotspot", func(s *WorkloadSpec) {
+			s.Distribution = DistributionHotspot
+			s.HotspotKeys = 0
+		}, "workload: HotspotKeys must be positive"},
+		{"distribution", func(s *WorkloadSpec) { s.Distribution = 3 }, "workload: unknown distribution 3"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := DefaultWorkloadSpec()
+			tc.modify(&spec)
+			_, err := NewWorkload(spec)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func objStoreEqual(a, b storetypes.ObjKVStore) bool {
+	iter1 := a.Iterator(nil, nil)
+	iter2 := b.Iterator(nil, nil)
+	defer iter1.Close()
+	defer iter2.Close()
+
+	for ; iter1.Valid() && iter2.Valid(); iter1.Next() {
+		if string(iter1.Key()) != string(iter2.Key()) || iter1.Value() != iter2.Value() {
+			return false
+		}
+		iter2.Next()
+	}
+	return !iter1.Valid() && !iter2.Valid()
+}